@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// chartSections are the tables `kana chart` prints for each script, in order.
+var chartSections = []struct {
+	Title  string
+	Groups []string
+}{
+	{Title: "", Groups: []string{"gojuon", "dakuten", "handakuten"}},
+	{Title: "yoon", Groups: []string{"yoon"}},
+	{Title: "extended", Groups: []string{"extended"}},
+}
+
+// runChart implements the `chart` subcommand.
+func runChart(flags *flagSet, args []string) error {
+	includeHiragana := flags.Bool("hiragana", true, "If we should show hiragana")
+	includeKatakana := flags.BoolP("katakana", "k", true, "If we should show katakana")
+	includeYoon := flags.BoolP("yoon", "y", true, "If we should show yoon (contracted sounds, e.g. kya)")
+	includeExtended := flags.BoolP("extended", "e", true, "If we should show extended katakana for foreign sounds (e.g. fa, ti, vu)")
+	rowNames := flags.String("rows", "", "A comma separated list of rows or groups to show (e.g. a,ka,sa or vowels,dakuten,yoon)")
+	romanization := flags.StringP("romanization", "r", string(romaji.Hepburn), "The romanization system to show (hepburn, kunrei, nihon or any)")
+	showWeights := flags.Bool("weights", false, "If we should overlay the profile's current selection weights")
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile to show weights from")
+	schedulerName := flags.StringP("scheduler", "s", schedulerWeighted, "The scheduler to show weights from (weighted, sm2 or leitner)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	system, err := romaji.ParseSystem(*romanization)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if *rowNames != "" {
+		if names, err = parseRows(*rowNames); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range rows {
+			if (listHas(rowGroups["yoon"], r.Name) && !*includeYoon) || (r.KatakanaOnly && !*includeExtended) {
+				continue
+			}
+			names = append(names, r.Name)
+		}
+	}
+
+	var scheduler Scheduler
+	if *showWeights {
+		prof, err := loadProfile(*profileName)
+		if err != nil {
+			return err
+		}
+		values := rowSet(names, *includeHiragana, *includeKatakana)
+		prof.init(values)
+		if scheduler, err = newScheduler(*schedulerName, values, prof, latencyPolicy{}, rand.New(rand.NewSource(time.Now().UnixNano())), time.Now); err != nil {
+			return err
+		}
+	}
+	return writeChart(os.Stdout, names, *includeHiragana, *includeKatakana, system, scheduler)
+}
+
+// writeChart writes the given rows of the chart with their readings in a
+// romanization system, for each included script.
+//
+// If a scheduler is given each kana is colored by, and shown with, its weight.
+func writeChart(wr io.Writer, names []string, includeHiragana, includeKatakana bool, system romaji.System, scheduler Scheduler) error {
+	values := rowSet(names, includeHiragana, includeKatakana)
+	var minWeight, maxWeight float64
+	if scheduler != nil {
+		minWeight, maxWeight = weightRange(values, scheduler)
+	}
+
+	cell := func(kana string) (gridCell, bool) {
+		if _, ok := values[kana]; !ok {
+			return gridCell{}, false
+		}
+		text := fmt.Sprintf("%s %s", kana, strings.Join(readings(kana, values[kana], system), "/"))
+		if scheduler == nil {
+			return gridCell{Text: text}, true
+		}
+		weight := scheduler.Weight(kana)
+		return gridCell{
+			Text:  fmt.Sprintf("%s %.2f", text, weight),
+			Color: weightColor(weight, minWeight, maxWeight),
+		}, true
+	}
+
+	var written bool
+	for _, script := range []romaji.Script{romaji.ScriptHiragana, romaji.ScriptKatakana} {
+		scriptRows := gridRows(values, script)
+		for _, section := range chartSections {
+			var sectionNames []string
+			for _, group := range section.Groups {
+				sectionNames = append(sectionNames, rowGroups[group]...)
+			}
+			var chart []row
+			for _, r := range scriptRows {
+				if listHas(sectionNames, r.Name) {
+					chart = append(chart, r)
+				}
+			}
+			if len(chart) == 0 {
+				continue
+			}
+
+			if written {
+				fmt.Fprintln(wr)
+			}
+			written = true
+			title := scriptNames[script]
+			if section.Title != "" {
+				title = title + " " + section.Title
+			}
+			fmt.Fprintf(wr, "%s:\n", strings.ToUpper(title[:1])+title[1:])
+			if err := writeGrid(wr, chart, script, cell); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/ansi"
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_writeChart(t *testing.T) {
+	assert := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(writeChart(buffer, []string{"sa", "sha", "fa"}, true, true, romaji.Hepburn, nil))
+	output := buffer.String()
+	assert.Contains(output, "Hiragana:\n")
+	assert.Contains(output, "Hiragana yoon:\n")
+	assert.Contains(output, "Katakana extended:\n")
+	assert.NotContains(output, "Hiragana extended:")
+	assert.Contains(output, "し shi")
+	assert.Contains(output, "シュ shu")
+	assert.Contains(output, "ファ fa")
+	assert.NotContains(output, "\033[", "the chart should be plain without weights")
+
+	buffer.Reset()
+	assert.Nil(writeChart(buffer, []string{"ta"}, false, true, romaji.Any, nil))
+	assert.True(strings.HasPrefix(buffer.String(), "Katakana:\n"))
+	assert.Contains(buffer.String(), "チ chi/ti")
+}
+
+func Test_writeChart_weights(t *testing.T) {
+	assert := assert.New(t)
+
+	values := rowSet([]string{"a"}, true, false)
+	p := newProfile("test")
+	p.init(values)
+	p.Weights["あ"] = weightMax
+	scheduler, err := newScheduler(schedulerWeighted, values, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.Nil(err)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(writeChart(buffer, []string{"a"}, true, false, romaji.Hepburn, scheduler))
+	assert.Contains(buffer.String(), ansi.Apply(ansi.ColorRed, "あ a 512.00"))
+	assert.Contains(buffer.String(), ansi.Apply(ansi.ColorGreen, "い i 1.00"))
+}
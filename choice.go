@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// Modes.
+const (
+	modeRecall = "recall"
+	modeChoice = "choice"
+)
+
+const (
+	choicesDefault = 4
+	choicesMin     = 4
+	choicesMax     = 6
+
+	distractorWeightDefault    = 1.0
+	distractorWeightConfusable = 16.0
+	distractorWeightPhonetic   = 4.0
+)
+
+// confusables are groups of kana that are easily mistaken for each other by sight.
+var confusables = [][]string{
+	{"シ", "ツ"},
+	{"ソ", "ン"},
+	{"シ", "ミ"},
+	{"ツ", "ソ"},
+	{"ク", "ケ", "タ"},
+	{"コ", "ユ", "ロ"},
+	{"チ", "テ"},
+	{"ナ", "メ"},
+	{"ウ", "ワ", "フ", "ラ"},
+	{"ア", "マ"},
+	{"ス", "ヌ"},
+	{"セ", "ヤ"},
+	{"ノ", "メ"},
+	{"ヲ", "ヨ"},
+	{"ぬ", "め"},
+	{"わ", "ね", "れ"},
+	{"あ", "お"},
+	{"い", "り", "こ"},
+	{"さ", "ち", "き"},
+	{"は", "ほ", "ま", "よ"},
+	{"る", "ろ"},
+	{"う", "つ", "ら"},
+	{"く", "へ"},
+	{"た", "な"},
+	{"け", "は"},
+	{"そ", "ろ"},
+}
+
+// validateMode returns an error if a mode or choice count isn't valid.
+func validateMode(mode string, choices int) error {
+	switch mode {
+	case modeRecall:
+		return nil
+	case modeChoice:
+		if choices < choicesMin || choices > choicesMax {
+			return fmt.Errorf("invalid choices: %d (expected between %d and %d)", choices, choicesMin, choicesMax)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid mode: %q (expected one of %s, %s)", mode, modeRecall, modeChoice)
+	}
+}
+
+// isConfusable returns if two kana are in the same confusable group.
+func isConfusable(a, b string) bool {
+	for _, group := range confusables {
+		if listHas(group, a) && listHas(group, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPhoneticallySimilar returns if two readings share a consonant or a vowel.
+func isPhoneticallySimilar(a, b string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	consonantA, consonantB := a[:len(a)-1], b[:len(b)-1]
+	if consonantA != "" && consonantA == consonantB {
+		return true
+	}
+	return len(a) == len(b) && a[len(a)-1] == b[len(b)-1]
+}
+
+// selectDistractors returns up to `count` kana to offer alongside a given kana.
+//
+// Kana that look alike, sound alike, or that have been answered
+// incorrectly before are preferred.
+func selectDistractors(random *rand.Rand, values map[string]string, kana string, system romaji.System, count int, incorrect map[string]int) []string {
+	reading := readings(kana, values[kana], system)[0]
+
+	candidates := make(map[string]string)
+	weights := make(map[string]float64)
+	for key, roman := range values {
+		candidateReading := readings(key, roman, system)[0]
+		if key == kana || candidateReading == reading {
+			continue
+		}
+		weight := distractorWeightDefault + float64(incorrect[key])
+		if isConfusable(kana, key) {
+			weight += distractorWeightConfusable
+		}
+		if isPhoneticallySimilar(reading, candidateReading) {
+			weight += distractorWeightPhonetic
+		}
+		candidates[key] = candidateReading
+		weights[key] = weight
+	}
+
+	var output []string
+	for len(output) < count && len(candidates) > 0 {
+		key, candidateReading := selectWeighted(random, candidates, weights)
+		output = append(output, key)
+		for other, otherReading := range candidates {
+			if otherReading == candidateReading {
+				delete(candidates, other)
+			}
+		}
+	}
+	return output
+}
+
+// formatChoices shuffles the options and formats them for display,
+// returning the (1 based) index of the correct option and the options
+// in their shuffled order.
+func formatChoices(random *rand.Rand, correct string, distractors []string) (string, int, []string) {
+	options := append([]string{correct}, distractors...)
+	var formatted, ordered []string
+	var answer int
+	for index, optionIndex := range random.Perm(len(options)) {
+		if optionIndex == 0 {
+			answer = index + 1
+		}
+		ordered = append(ordered, options[optionIndex])
+		formatted = append(formatted, fmt.Sprintf("[%d] %s", index+1, options[optionIndex]))
+	}
+	return strings.Join(formatted, "  "), answer, ordered
+}
+
+// chosenOption returns the option picked by its number, or the answer
+// as typed if it isn't the number of an option.
+func chosenOption(options []string, actual string) string {
+	if index, err := strconv.Atoi(actual); err == nil && index > 0 && index <= len(options) {
+		return options[index-1]
+	}
+	return actual
+}
+
+// choiceQuestion builds a multiple choice prompt for a kana, returning the
+// question, the accepted answer, the correct option for display, and the
+// options in the order they're numbered.
+func choiceQuestion(random *rand.Rand, values map[string]string, kana, direction string, system romaji.System, choices int, incorrect map[string]int) (question, answer, correction string, options []string) {
+	if direction == directionCross {
+		// when both scripts are quizzed the counterparts are in both scripts
+		// too, so only offer the ones in the same script as the answer.
+		correct := scriptPairs[kana]
+		candidates := make(map[string]string)
+		for key, roman := range counterpartSet(values) {
+			if scriptName(key) == scriptName(correct) {
+				candidates[key] = roman
+			}
+		}
+		formatted, index, options := formatChoices(random, correct, selectDistractors(random, candidates, correct, system, choices-1, incorrect))
+		return fmt.Sprintf("%s  %s", kana, formatted), strconv.Itoa(index), fmt.Sprintf("[%d] %s", index, correct), options
+	}
+
+	distractors := selectDistractors(random, values, kana, system, choices-1, incorrect)
+	correct, prompt := readings(kana, values[kana], system)[0], kana
+	if direction == directionReverse {
+		correct, prompt = kana, readings(kana, values[kana], system)[0]
+	} else {
+		for index, distractor := range distractors {
+			distractors[index] = readings(distractor, values[distractor], system)[0]
+		}
+	}
+	formatted, index, options := formatChoices(random, correct, distractors)
+	return fmt.Sprintf("%s  %s", prompt, formatted), strconv.Itoa(index), fmt.Sprintf("[%d] %s", index, correct), options
+}
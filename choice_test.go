@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_selectDistractors(t *testing.T) {
+	assert := assert.New(t)
+
+	values := mergeSets(romaji.Katakana, romaji.Hiragana)
+	distractors := selectDistractors(testRandom(), values, "シ", romaji.Hepburn, 5, nil)
+	assert.Len(distractors, 5)
+	assert.False(listHas(distractors, "シ"))
+	assert.False(listHas(distractors, "し"), "kana with the same reading are not valid distractors")
+
+	var seen []string
+	for _, distractor := range distractors {
+		reading := values[distractor]
+		assert.False(listHas(seen, reading), "distractors should have unique readings")
+		seen = append(seen, reading)
+	}
+
+	counts := make(map[string]int)
+	random := testRandom()
+	for x := 0; x < 256; x++ {
+		for _, distractor := range selectDistractors(random, values, "シ", romaji.Hepburn, 3, nil) {
+			counts[distractor]++
+		}
+	}
+	assert.True(counts["ツ"] > counts["ポ"], "confusable kana should be preferred")
+}
+
+func Test_choiceQuestion(t *testing.T) {
+	assert := assert.New(t)
+
+	question, answer, correction, _ := choiceQuestion(testRandom(), romaji.Hiragana, "ぬ", directionForward, romaji.Hepburn, 4, nil)
+	assert.True(strings.HasPrefix(question, "ぬ"))
+	assert.Contains(question, "["+answer+"] nu")
+	assert.Equal("["+answer+"] nu", correction)
+
+	question, answer, _, _ = choiceQuestion(testRandom(), romaji.Hiragana, "ぬ", directionReverse, romaji.Hepburn, 4, nil)
+	assert.True(strings.HasPrefix(question, "nu"))
+	assert.Contains(question, "["+answer+"] ぬ")
+
+	assert.Nil(validateMode(modeChoice, 4))
+	assert.Nil(validateMode(modeChoice, 6))
+	assert.NotNil(validateMode(modeChoice, 3))
+	assert.NotNil(validateMode(modeChoice, 7))
+	assert.NotNil(validateMode("essay", 4))
+}
+
+func Test_choiceQuestion_cross(t *testing.T) {
+	assert := assert.New(t)
+
+	question, answer, correction, _ := choiceQuestion(testRandom(), romaji.Hiragana, "ぬ", directionCross, romaji.Hepburn, 4, nil)
+	assert.True(strings.HasPrefix(question, "ぬ"))
+	assert.Equal("["+answer+"] ヌ", correction)
+	assert.NotContains(question, "ね", "options should all be in the other script")
+
+	// with both scripts quizzed, the options are still all in the answer's script.
+	_, _, _, options := choiceQuestion(testRandom(), mergeSets(romaji.Hiragana, romaji.Katakana), "ざ", directionCross, romaji.Hepburn, 4, nil)
+	assert.Len(options, 4)
+	for _, option := range options {
+		assert.Equal(scriptName("ザ"), scriptName(option), "options should all be in the other script")
+	}
+}
+
+func Test_chosenOption(t *testing.T) {
+	assert := assert.New(t)
+
+	options := []string{"nu", "su", "me"}
+	assert.Equal("su", chosenOption(options, "2"))
+	assert.Equal("4", chosenOption(options, "4"))
+	assert.Equal("nu", chosenOption(nil, "nu"))
+}
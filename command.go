@@ -0,0 +1,231 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const commandDefault = "drill"
+
+// command is a subcommand of the cli, e.g. `kana stats`.
+type command struct {
+	Name     string
+	Args     string
+	Summary  string
+	Examples []string
+	Run      func(flags *flagSet, args []string) error
+}
+
+// commands are the subcommands in the order they're listed in help.
+var commands = []command{
+	{
+		Name:    "drill",
+		Summary: "Quiz kana, saving progress to a profile (the default command)",
+		Examples: []string{
+			"kana",
+			"kana drill --katakana=false --rows=a,ka,sa",
+			"kana drill -p alice --scheduler=sm2 --direction=both",
+			"kana drill --words --mode=choice",
+		},
+		Run: runDrill,
+	},
+	{
+		Name:    "stats",
+		Summary: "Report long term progress from the event log",
+		Examples: []string{
+			"kana stats",
+			"kana stats --since=30d --script=katakana",
+			"kana stats -f csv > stats.csv",
+		},
+		Run: runStats,
+	},
+	{
+		Name:    "report",
+		Summary: "Write progress as a self contained HTML page",
+		Examples: []string{
+			"kana report",
+			"kana report -p alice -o alice.html --days=90",
+		},
+		Run: runReport,
+	},
+	{
+		Name:    "chart",
+		Summary: "Print the answer key laid out like the gojuon chart",
+		Examples: []string{
+			"kana chart",
+			"kana chart --rows=gojuon --katakana=false",
+			"kana chart --weights -p alice",
+		},
+		Run: runChart,
+	},
+	{
+		Name:    "export",
+		Summary: "Write a profile as JSON",
+		Examples: []string{
+			"kana export -p alice > alice.json",
+			"kana export -p alice -o alice.json",
+		},
+		Run: runExport,
+	},
+	{
+		Name:    "import",
+		Args:    "<file>",
+		Summary: "Load a profile written by export",
+		Examples: []string{
+			"kana import alice.json",
+			"kana import -p bob --force alice.json",
+		},
+		Run: runImport,
+	},
+	{
+		Name:    "reset",
+		Summary: "Delete a profile's progress",
+		Examples: []string{
+			"kana reset -p alice",
+			"kana reset -p alice --events --yes",
+		},
+		Run: runReset,
+	},
+}
+
+// findCommand returns a command by name.
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// runCommand runs the command named by the first argument, or the
+// default command if the first argument is a flag.
+func runCommand(args []string) error {
+	name := commandDefault
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		if len(args) == 0 {
+			printCommands(os.Stdout)
+			return nil
+		}
+		name, args = args[0], []string{"--help"}
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		return fmt.Errorf("invalid command: %q (see kana help)", name)
+	}
+	return cmd.Run(newFlagSet(cmd), args)
+}
+
+// printCommands prints the list of commands.
+func printCommands(wr io.Writer) {
+	fmt.Fprintln(wr, "Usage: kana [command] [flags]")
+	fmt.Fprintln(wr)
+	fmt.Fprintln(wr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(wr, "  %s%s\n", padDisplay(cmd.Name, 8), cmd.Summary)
+	}
+	fmt.Fprintln(wr)
+	fmt.Fprintln(wr, "Use `kana <command> --help` to see a command's flags and examples.")
+}
+
+// flagSet is the flags for a command.
+//
+// Flags can have a long and a short name (e.g. `--profile` and `-p`),
+// which are shown together in the command's help.
+type flagSet struct {
+	*flag.FlagSet
+	command command
+	shorts  map[string]string
+}
+
+// newFlagSet returns a flag set for a command.
+func newFlagSet(cmd command) *flagSet {
+	flags := &flagSet{
+		FlagSet: flag.NewFlagSet(cmd.Name, flag.ExitOnError),
+		command: cmd,
+		shorts:  make(map[string]string),
+	}
+	flags.Usage = func() { flags.printUsage(flags.Output()) }
+	return flags
+}
+
+// BoolP defines a bool flag with a long and short name.
+func (fs *flagSet) BoolP(long, short string, defaultValue bool, usage string) *bool {
+	value := fs.Bool(long, defaultValue, usage)
+	fs.BoolVar(value, short, defaultValue, usage)
+	fs.shorts[long] = short
+	return value
+}
+
+// IntP defines an int flag with a long and short name.
+func (fs *flagSet) IntP(long, short string, defaultValue int, usage string) *int {
+	value := fs.Int(long, defaultValue, usage)
+	fs.IntVar(value, short, defaultValue, usage)
+	fs.shorts[long] = short
+	return value
+}
+
+// StringP defines a string flag with a long and short name.
+func (fs *flagSet) StringP(long, short string, defaultValue string, usage string) *string {
+	value := fs.String(long, defaultValue, usage)
+	fs.StringVar(value, short, defaultValue, usage)
+	fs.shorts[long] = short
+	return value
+}
+
+// printUsage prints the command's usage, flags and examples.
+func (fs *flagSet) printUsage(wr io.Writer) {
+	usage := "Usage: kana " + fs.command.Name + " [flags]"
+	if fs.command.Args != "" {
+		usage += " " + fs.command.Args
+	}
+	fmt.Fprintln(wr, usage)
+	fmt.Fprintln(wr)
+	fmt.Fprintln(wr, fs.command.Summary+".")
+	fmt.Fprintln(wr)
+	fmt.Fprintln(wr, "Flags:")
+
+	isShort := make(map[string]bool)
+	for _, short := range fs.shorts {
+		isShort[short] = true
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if isShort[f.Name] {
+			return
+		}
+		name := "--" + f.Name
+		if short, ok := fs.shorts[f.Name]; ok {
+			name = "-" + short + ", " + name
+		}
+		valueName, usage := flag.UnquoteUsage(f)
+		if valueName != "" {
+			name += " " + valueName
+		}
+		switch {
+		case f.DefValue == "" || f.DefValue == "0" || f.DefValue == "0s" || f.DefValue == "false":
+		case valueName == "string":
+			usage += fmt.Sprintf(" (default %q)", f.DefValue)
+		default:
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(wr, "  %s\n    \t%s\n", name, usage)
+	})
+
+	if len(fs.command.Examples) > 0 {
+		fmt.Fprintln(wr)
+		fmt.Fprintln(wr, "Examples:")
+		for _, example := range fs.command.Examples {
+			fmt.Fprintf(wr, "  %s\n", example)
+		}
+	}
+	if fs.command.Name == commandDefault {
+		fmt.Fprintln(wr)
+		printCommands(wr)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func Test_findCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd, ok := findCommand("stats")
+	assert.True(ok)
+	assert.Equal("stats", cmd.Name)
+
+	_, ok = findCommand("quiz")
+	assert.False(ok)
+	assert.NotNil(runCommand([]string{"quiz"}))
+
+	_, ok = findCommand(commandDefault)
+	assert.True(ok, "the default command should exist")
+}
+
+func Test_flagSet(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd, _ := findCommand("stats")
+	flags := newFlagSet(cmd)
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile")
+	verbose := flags.BoolP("verbose", "v", false, "If we should be verbose")
+	count := flags.IntP("count", "c", 3, "The count")
+	assert.Nil(flags.Parse([]string{"-p", "alice", "--verbose", "--count=5", "extra"}))
+	assert.Equal("alice", *profileName)
+	assert.True(*verbose)
+	assert.Equal(5, *count)
+	assert.Equal([]string{"extra"}, flags.Args())
+
+	buffer := new(bytes.Buffer)
+	flags.printUsage(buffer)
+	usage := buffer.String()
+	assert.True(strings.HasPrefix(usage, "Usage: kana stats [flags]\n"))
+	assert.Contains(usage, "  -p, --profile string\n    \tThe profile (default \"default\")\n")
+	assert.Contains(usage, "  -v, --verbose\n    \tIf we should be verbose\n")
+	assert.Contains(usage, "(default 3)")
+	assert.NotContains(usage, "  -p string", "short flags should be shown with their long name")
+	assert.Contains(usage, "Examples:\n  kana stats\n")
+	assert.NotContains(usage, "Commands:")
+}
+
+func Test_printCommands(t *testing.T) {
+	assert := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	printCommands(buffer)
+	for _, cmd := range commands {
+		assert.Contains(buffer.String(), "  "+padDisplay(cmd.Name, 8)+cmd.Summary+"\n")
+	}
+
+	cmd, _ := findCommand(commandDefault)
+	buffer.Reset()
+	newFlagSet(cmd).printUsage(buffer)
+	assert.Contains(buffer.String(), "Commands:", "the default command's help should list the commands")
+}
+
+func Test_commands_help(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// -h shows every command's help rather than being taken by a flag.
+	for _, cmd := range commands {
+		output := new(bytes.Buffer)
+		flags := newFlagSet(cmd)
+		flags.Init(cmd.Name, flag.ContinueOnError)
+		flags.SetOutput(output)
+		assert.Equal(flag.ErrHelp, cmd.Run(flags, []string{"-h"}), cmd.Name)
+		assert.Contains(output.String(), "Usage: kana "+cmd.Name, cmd.Name)
+	}
+}
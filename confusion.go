@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+const confusionsShown = 10
+
+// confusion is a wrong answer given for a kana, and how many times it was given.
+type confusion struct {
+	Kana   string `json:"kana"`
+	Answer string `json:"answer"`
+	Count  int    `json:"count"`
+}
+
+// String returns the confusion as a sentence, e.g. `ヌ answered as 'su' 7 times`.
+func (c confusion) String() string {
+	if c.Count == 1 {
+		return fmt.Sprintf("%s answered as '%s' once", c.Kana, c.Answer)
+	}
+	return fmt.Sprintf("%s answered as '%s' %d times", c.Kana, c.Answer, c.Count)
+}
+
+// recordConfusion counts a wrong answer for a kana.
+func recordConfusion(confusions map[string]map[string]int, kana, answer string) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return
+	}
+	if confusions[kana] == nil {
+		confusions[kana] = make(map[string]int)
+	}
+	confusions[kana][answer]++
+}
+
+// topConfusions returns the most common confusions, most common first.
+func topConfusions(confusions map[string]map[string]int, count int) []confusion {
+	output := sortConfusions(confusions)
+	if len(output) > count {
+		output = output[:count]
+	}
+	return output
+}
+
+// sortConfusions returns every confusion, most common first, then in chart order.
+func sortConfusions(confusions map[string]map[string]int) []confusion {
+	var output []confusion
+	for kana, answers := range confusions {
+		for answer, answerCount := range answers {
+			output = append(output, confusion{Kana: kana, Answer: answer, Count: answerCount})
+		}
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Count != output[j].Count {
+			return output[i].Count > output[j].Count
+		}
+		if output[i].Kana != output[j].Kana {
+			return kanaLess(output[i].Kana, output[j].Kana)
+		}
+		return output[i].Answer < output[j].Answer
+	})
+	return output
+}
+
+// confusedWith returns the kana in a set that a wrong answer belongs to,
+// preferring kana in the same script as the kana that was asked.
+//
+// The answer is either a kana itself (i.e. for reverse and cross prompts)
+// or one of the readings of a kana.
+func confusedWith(values map[string]string, kana, answer string, system romaji.System) (string, bool) {
+	if _, ok := values[answer]; ok && answer != kana {
+		return answer, true
+	}
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sortKana(keys)
+
+	var match string
+	for _, key := range keys {
+		if key == kana || !listHas(readings(key, values[key], system), answer) {
+			continue
+		}
+		if scriptName(key) == scriptName(kana) {
+			return key, true
+		}
+		if match == "" {
+			match = key
+		}
+	}
+	return match, match != ""
+}
+
+// confusionPairs returns the kana in a set paired with the kana they're
+// most often confused with, for the top `count` confusions.
+func confusionPairs(values map[string]string, confusions map[string]map[string]int, count int, system romaji.System) map[string]string {
+	output := make(map[string]string)
+	var found int
+	for _, c := range sortConfusions(confusions) {
+		if found == count {
+			break
+		}
+		if _, ok := values[c.Kana]; !ok {
+			continue
+		}
+		partner, ok := confusedWith(values, c.Kana, c.Answer, system)
+		if !ok {
+			continue
+		}
+		found++
+		if _, ok := output[c.Kana]; !ok {
+			output[c.Kana] = partner
+		}
+		if _, ok := output[partner]; !ok {
+			output[partner] = c.Kana
+		}
+	}
+	return output
+}
+
+// confusionScheduler wraps a scheduler, following a kana with the kana it's
+// most often confused with so confused pairs are drilled back-to-back.
+type confusionScheduler struct {
+	Scheduler
+	values     map[string]string
+	confusions map[string]map[string]int
+	count      int
+	system     romaji.System
+	queued     string
+}
+
+// Next returns a queued partner if there is one, otherwise the next kana from
+// the wrapped scheduler, queueing its partner.
+func (cs *confusionScheduler) Next(exclude []string) string {
+	if cs.queued != "" {
+		kana := cs.queued
+		cs.queued = ""
+		return kana
+	}
+	kana := cs.Scheduler.Next(exclude)
+	cs.queued = confusionPairs(cs.values, cs.confusions, cs.count, cs.system)[kana]
+	return kana
+}
+
+// printConfusions prints the most common confusions, one per line.
+func printConfusions(wr io.Writer, confusions []confusion) {
+	if len(confusions) == 0 {
+		return
+	}
+	fmt.Fprintln(wr, "Confusions:")
+	for _, c := range confusions {
+		fmt.Fprintf(wr, "  %v\n", c)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_recordConfusion(t *testing.T) {
+	assert := assert.New(t)
+
+	confusions := make(map[string]map[string]int)
+	recordConfusion(confusions, "ヌ", "su")
+	recordConfusion(confusions, "ヌ", " SU ")
+	recordConfusion(confusions, "ヌ", "")
+	recordConfusion(confusions, "シ", "tsu")
+	assert.Equal(2, confusions["ヌ"]["su"])
+	assert.Len(confusions["ヌ"], 1, "blank answers shouldn't count")
+
+	top := topConfusions(confusions, 1)
+	assert.Len(top, 1)
+	assert.Equal("ヌ answered as 'su' 2 times", top[0].String())
+	assert.Equal("シ answered as 'tsu' once", topConfusions(confusions, 2)[1].String())
+}
+
+func Test_confusedWith(t *testing.T) {
+	assert := assert.New(t)
+
+	values := mergeSets(romaji.Hiragana, romaji.Katakana)
+
+	partner, ok := confusedWith(values, "ヌ", "su", romaji.Hepburn)
+	assert.True(ok)
+	assert.Equal("ス", partner, "the same script should be preferred")
+
+	partner, ok = confusedWith(values, "ヌ", "ス", romaji.Hepburn)
+	assert.True(ok)
+	assert.Equal("ス", partner)
+
+	_, ok = confusedWith(values, "ヌ", "xyz", romaji.Hepburn)
+	assert.False(ok)
+}
+
+func Test_confusionScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	values := mergeSets(romaji.Katakana)
+	p := newProfile("test")
+	p.init(values)
+	recordConfusion(p.Confusions, "ヌ", "su")
+
+	pairs := confusionPairs(values, p.Confusions, 1, romaji.Hepburn)
+	assert.Equal("ス", pairs["ヌ"])
+	assert.Equal("ヌ", pairs["ス"])
+
+	inner, err := newScheduler(schedulerWeighted, values, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.Nil(err)
+	scheduler := &confusionScheduler{Scheduler: inner, values: values, confusions: p.Confusions, count: 1, system: romaji.Hepburn}
+	for x := 0; x < 100; x++ {
+		if kana := scheduler.Next(nil); kana == "ヌ" || kana == "ス" {
+			assert.Equal(pairs[kana], scheduler.Next(nil), "confused kana should be drilled back-to-back")
+		}
+	}
+}
+
+func Test_printConfusions(t *testing.T) {
+	assert := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	printConfusions(buffer, nil)
+	assert.Empty(buffer.String())
+
+	printConfusions(buffer, []confusion{{Kana: "ヌ", Answer: "su", Count: 7}})
+	assert.Equal("Confusions:\n  ヌ answered as 'su' 7 times\n", buffer.String())
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// Directions.
+const (
+	directionForward = "forward"
+	directionReverse = "reverse"
+	directionBoth    = "both"
+	directionCross   = "cross"
+)
+
+const (
+	halfWidthStart    = 'ｦ'
+	halfWidthDakuten  = 'ﾞ'
+	halfWidthHandaku  = 'ﾟ'
+	fullWidthASCIIMin = '！'
+	fullWidthASCIIMax = '～'
+	fullWidthOffset   = fullWidthASCIIMin - '!'
+)
+
+// halfWidthKatakana are the full-width equivalents of the half-width
+// katakana block, in code point order starting at `halfWidthStart`.
+var halfWidthKatakana = []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+// validateDirection returns an error if a direction isn't known.
+func validateDirection(direction string) error {
+	switch direction {
+	case directionForward, directionReverse, directionBoth, directionCross:
+		return nil
+	default:
+		return fmt.Errorf("invalid direction: %q (expected one of %s, %s, %s, %s)", direction, directionForward, directionReverse, directionBoth, directionCross)
+	}
+}
+
+// selectDirection resolves the direction for a single prompt.
+func selectDirection(random *rand.Rand, direction string) string {
+	if direction == directionBoth {
+		if random.Intn(2) == 0 {
+			return directionForward
+		}
+		return directionReverse
+	}
+	return direction
+}
+
+// scriptPairs maps each kana to its counterpart in the other script, e.g. か to カ.
+var scriptPairs = pairScripts(mergeSets(romaji.Hiragana, romaji.HiraganaYoon), mergeSets(romaji.Katakana, romaji.KatakanaYoon))
+
+// pairScripts derives a pairing table between a hiragana and a katakana set.
+//
+// Only kana that have a counterpart with the same reading are paired.
+func pairScripts(hiraganaSet, katakanaSet map[string]string) map[string]string {
+	output := make(map[string]string)
+	for kana, roman := range hiraganaSet {
+		counterpart := romaji.ToKatakana(kana)
+		if katakanaSet[counterpart] == roman {
+			output[kana] = counterpart
+			output[counterpart] = kana
+		}
+	}
+	return output
+}
+
+// counterpartSet returns the counterparts of the kana in a set.
+func counterpartSet(values map[string]string) map[string]string {
+	output := make(map[string]string)
+	for kana, roman := range values {
+		if counterpart, ok := scriptPairs[kana]; ok {
+			output[counterpart] = roman
+		}
+	}
+	return output
+}
+
+// readings returns the accepted readings of a kana (or word) in a given
+// system, falling back to its hepburn reading if it can't be romanized.
+//
+// The first reading is the canonical one, i.e. the one we show as the correction.
+func readings(kana, hepburn string, system romaji.System) []string {
+	output, err := romaji.Romanize(kana, system)
+	if err != nil {
+		return []string{hepburn}
+	}
+	return output
+}
+
+// scriptName returns the name of the script a kana string is written in.
+func scriptName(value string) string {
+	hasHiragana := romaji.ToKatakana(value) != value
+	hasKatakana := romaji.ToHiragana(value) != value
+	switch {
+	case hasHiragana && hasKatakana:
+		return "mixed"
+	case hasHiragana:
+		return "hiragana"
+	case hasKatakana:
+		return "katakana"
+	default:
+		return ""
+	}
+}
+
+// kanaWithReading returns the kana in a set that share a given kana's
+// canonical reading in a given romanization system.
+func kanaWithReading(values map[string]string, kana string, system romaji.System) []string {
+	reading := readings(kana, values[kana], system)[0]
+	var others []string
+	for key, roman := range values {
+		if key != kana && readings(key, roman, system)[0] == reading {
+			others = append(others, key)
+		}
+	}
+	sortKana(others)
+	return append([]string{kana}, others...)
+}
+
+// kanaNormalizer returns a function that normalizes typed kana for comparison.
+//
+// Width differences are always ignored; if only one script is in
+// the active set, answers in the other script are converted to it.
+func kanaNormalizer(includeHiragana, includeKatakana bool) func(string) string {
+	return func(value string) string {
+		value = normalizeWidth(value)
+		switch {
+		case includeHiragana && !includeKatakana:
+			return romaji.ToHiragana(value)
+		case includeKatakana && !includeHiragana:
+			return romaji.ToKatakana(value)
+		default:
+			return value
+		}
+	}
+}
+
+// normalizeRomaji normalizes typed romaji for comparison.
+func normalizeRomaji(value string) string {
+	return strings.ToLower(normalizeWidth(value))
+}
+
+// normalizeWidth converts full-width ascii to ascii and half-width
+// katakana to full-width katakana, i.e. roughly NFKC for the characters
+// an IME is likely to produce.
+func normalizeWidth(value string) string {
+	var output strings.Builder
+	for _, r := range value {
+		switch {
+		case r >= fullWidthASCIIMin && r <= fullWidthASCIIMax:
+			output.WriteRune(r - fullWidthOffset)
+		case r == '　':
+			output.WriteRune(' ')
+		case r == halfWidthDakuten || r == halfWidthHandaku:
+			combineSoundMark(&output, r == halfWidthHandaku)
+		case r >= halfWidthStart && int(r-halfWidthStart) < len(halfWidthKatakana):
+			output.WriteRune(halfWidthKatakana[r-halfWidthStart])
+		default:
+			output.WriteRune(r)
+		}
+	}
+	return output.String()
+}
+
+// combineSoundMark applies a dakuten or handakuten to the last rune written.
+func combineSoundMark(output *strings.Builder, handakuten bool) {
+	current := output.String()
+	last, size := utf8.DecodeLastRuneInString(current)
+	var combined rune
+	switch {
+	case last == 'ウ' && !handakuten:
+		combined = 'ヴ'
+	case handakuten && strings.ContainsRune("ハヒフヘホ", last):
+		combined = last + 2
+	case !handakuten && strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", last):
+		combined = last + 1
+	}
+	if combined == 0 {
+		if handakuten {
+			output.WriteRune('゜')
+		} else {
+			output.WriteRune('゛')
+		}
+		return
+	}
+	output.Reset()
+	output.WriteString(current[:len(current)-size])
+	output.WriteRune(combined)
+}
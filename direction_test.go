@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_kanaWithReading(t *testing.T) {
+	assert := assert.New(t)
+
+	values := mergeSets(romaji.Hiragana, romaji.Katakana)
+	matches := kanaWithReading(values, "じ", romaji.Hepburn)
+	assert.Equal("じ", matches[0])
+	assert.Len(matches, 4)
+
+	matches = kanaWithReading(values, "じ", romaji.Nihon)
+	assert.Len(matches, 2)
+
+	// the extended ti, di and tu don't share a reading with ち, ぢ and つ
+	// in kunrei-shiki and nihon-shiki.
+	extended := mergeSets(values, romaji.KatakanaExtended)
+	for _, system := range []romaji.System{romaji.Kunrei, romaji.Nihon} {
+		assert.Equal([]string{"チ", "ち"}, kanaWithReading(extended, "チ", system), system)
+		assert.Equal([]string{"ティ"}, kanaWithReading(extended, "ティ", system), system)
+		assert.Equal([]string{"ディ"}, kanaWithReading(extended, "ディ", system), system)
+		assert.Equal([]string{"トゥ"}, kanaWithReading(extended, "トゥ", system), system)
+		assert.Equal([]string{"ドゥ"}, kanaWithReading(extended, "ドゥ", system), system)
+	}
+	assert.Equal([]string{"ぢ", "じ", "ジ", "ヂ"}, kanaWithReading(extended, "ぢ", romaji.Hepburn), "other matches are in chart order")
+}
+
+func Test_normalizeWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("ka", normalizeWidth("ｋａ"))
+	assert.Equal("カ", normalizeWidth("ｶ"))
+	assert.Equal("ガ", normalizeWidth("ｶﾞ"))
+	assert.Equal("パン", normalizeWidth("ﾊﾟﾝ"))
+	assert.Equal("ヴ", normalizeWidth("ｳﾞ"))
+}
+
+func Test_kanaNormalizer(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("か", kanaNormalizer(true, false)("カ"))
+	assert.Equal("カ", kanaNormalizer(false, true)("か"))
+	assert.Equal("カ", kanaNormalizer(false, true)("ｶ"))
+	assert.Equal("か", kanaNormalizer(true, true)("か"))
+	assert.Equal("シャ", romaji.ToKatakana("しゃ"))
+	assert.Equal("しゃ", romaji.ToHiragana("シャ"))
+}
+
+func Test_scriptPairs(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("カ", scriptPairs["か"])
+	assert.Equal("か", scriptPairs["カ"])
+	assert.Equal("シャ", scriptPairs["しゃ"])
+	assert.Len(scriptPairs, len(romaji.Hiragana)+len(romaji.Katakana)+len(romaji.HiraganaYoon)+len(romaji.KatakanaYoon))
+
+	counterparts := counterpartSet(map[string]string{"か": "ka", "き": "ki"})
+	assert.Equal(map[string]string{"カ": "ka", "キ": "ki"}, counterparts)
+}
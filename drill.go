@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// runDrill implements the `drill` command, quizzing kana until the
+// learner quits or interrupts.
+func runDrill(flags *flagSet, args []string) error {
+	includeKatakana := flags.BoolP("katakana", "k", true, "If we should quiz katakana")
+	includeHiragana := flags.Bool("hiragana", true, "If we should quiz hiragana")
+	includeYoon := flags.BoolP("yoon", "y", false, "If we should quiz yoon (contracted sounds, e.g. kya)")
+	includeExtended := flags.BoolP("extended", "e", false, "If we should quiz extended katakana for foreign sounds (e.g. fa, ti, vu)")
+	includeWords := flags.BoolP("words", "w", false, "If we should quiz words instead of single kana")
+	wordList := flags.String("wordlist", "", "A word list file to use with --words (defaults to the bundled list)")
+	rowNames := flags.String("rows", "", "A comma separated list of rows or groups to quiz (e.g. a,ka,sa or vowels,dakuten,yoon)")
+	lesson := flags.Int("lesson", 0, "A lesson number; lesson N quizzes the first N rows in the order they're typically taught")
+	progressive := flags.Bool("progressive", false, "If we should start with the vowels and unlock each following row as the kana are mastered")
+	masteryLatency := flags.Duration("mastery-latency", masteryLatencyDefault, "The p50 answer time a kana needs to be under to count as mastered in progressive mode")
+	slowRatio := flags.Float64("slow-ratio", slowRatioDefault, "How many times slower than your median a correct answer can be before it counts as a partial failure (0 to disable)")
+	slowSamples := flags.Int("slow-samples", slowSamplesDefault, "How many answer times are needed before slow answers are penalized")
+	limit := flags.IntP("limit", "l", 0, "A limit for the number of kana to test")
+	mode := flags.StringP("mode", "m", modeRecall, "The quiz mode (recall to type the answer, or choice to pick from numbered options)")
+	choices := flags.IntP("choices", "c", choicesDefault, "The number of options to show in choice mode")
+	direction := flags.StringP("direction", "d", directionForward, "The direction to quiz in (forward shows kana, reverse shows romaji, both, or cross shows kana and asks for the other script)")
+	romanization := flags.StringP("romanization", "r", string(romaji.Hepburn), "The romanization system to accept (hepburn, kunrei, nihon or any)")
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile to load and save progress to")
+	schedulerName := flags.StringP("scheduler", "s", schedulerWeighted, "The scheduler to select kana with (weighted, sm2 or leitner)")
+	drillConfusions := flags.Int("drill-confusions", 0, "The number of most confused pairs (e.g. ヌ and ス) to drill back-to-back when either comes up")
+	results := flags.String("results", resultsBoth, "How to show results at the end of a session (table, grid, or both)")
+	gridColor := flags.String("grid-color", gridColorAccuracy, "What to color the results grid by (accuracy or weight)")
+	logPath := flags.String("log", "", "The file to append a JSON line to for each answer (defaults to the profile's log)")
+	script := flags.String("script", "", "A file of answers to read, one per line, instead of asking (input piped to stdin is read the same way)")
+	timeLimit := flags.Duration("time-limit", 0, "How long the session lasts before it ends, e.g. 60s (0 for no limit)")
+	answerTimeout := flags.Duration("answer-timeout", 0, "How long each prompt can go unanswered before it's marked incorrect, e.g. 3s (0 for no limit)")
+	noSave := flags.Bool("no-save", false, "If we should start from an empty profile and not save it or log answers, e.g. so a scripted session prints the same thing every time")
+	seed := flags.Int64("seed", 0, "A seed for the random choices so a session can be replayed (0 picks one at random, shown at the end of the session)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	system, err := romaji.ParseSystem(*romanization)
+	if err != nil {
+		return err
+	}
+	if err = validateDirection(*direction); err != nil {
+		return err
+	}
+	if err = validateMode(*mode, *choices); err != nil {
+		return err
+	}
+	if err = validateResults(*results, *gridColor); err != nil {
+		return err
+	}
+	if err = validateSelection(*progressive, *includeWords, *rowNames, *lesson); err != nil {
+		return err
+	}
+	if *timeLimit < 0 || *answerTimeout < 0 {
+		return fmt.Errorf("invalid time limit: the session and answer time limits can't be negative")
+	}
+
+	var values, meanings map[string]string
+	var sets []map[string]string
+	if *includeKatakana {
+		sets = append(sets, romaji.Katakana)
+		if *includeYoon {
+			sets = append(sets, romaji.KatakanaYoon)
+		}
+		if *includeExtended {
+			sets = append(sets, romaji.KatakanaExtended)
+		}
+	}
+	if *includeHiragana {
+		sets = append(sets, romaji.Hiragana)
+		if *includeYoon {
+			sets = append(sets, romaji.HiraganaYoon)
+		}
+	}
+	prof := newProfile(*profileName)
+	if !*noSave {
+		if prof, err = loadProfile(*profileName); err != nil {
+			return err
+		}
+	}
+
+	progress := &progression{
+		profile:         prof,
+		includeHiragana: *includeHiragana,
+		includeKatakana: *includeKatakana,
+		masteryLatency:  *masteryLatency,
+	}
+
+	if *progressive {
+		values = progress.pool()
+	} else if *includeWords {
+		values, meanings, err = wordSet(*wordList, *includeHiragana, *includeKatakana)
+		if err != nil {
+			return err
+		}
+	} else if *rowNames != "" || *lesson > 0 {
+		var names []string
+		if *lesson > 0 {
+			names, err = lessonRows(*lesson)
+			if err != nil {
+				return err
+			}
+		}
+		selected, err := parseRows(*rowNames)
+		if err != nil {
+			return err
+		}
+		values = rowSet(append(names, selected...), *includeHiragana, *includeKatakana)
+	} else {
+		values = mergeSets(sets...)
+	}
+
+	if *limit > 0 {
+		values = selectCount(values, *limit)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("no kana match the selected rows and scripts")
+	}
+
+	prof.init(values)
+
+	// the session and the scheduler share a clock, so the scheduler sees
+	// the same time the session does.
+	now := time.Now
+	if *seed == 0 {
+		*seed = now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(*seed))
+
+	// scripted answers don't say anything about how quickly the learner
+	// answers, so slow answers aren't penalized and the results of a script
+	// don't depend on timing.
+	input, scripted := io.Reader(os.Stdin), !isTerminal(os.Stdin)
+	if *script != "" {
+		f, err := os.Open(*script)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input, scripted = f, true
+	}
+	latency := latencyPolicy{
+		SlowRatio:  *slowRatio,
+		MinSamples: *slowSamples,
+		Times:      prof.times,
+	}
+	if scripted {
+		latency = latencyPolicy{}
+	}
+	scheduler, err := newScheduler(*schedulerName, values, prof, latency, random, now)
+	if err != nil {
+		return err
+	}
+	if *drillConfusions > 0 {
+		scheduler = &confusionScheduler{
+			Scheduler:  scheduler,
+			values:     values,
+			confusions: prof.Confusions,
+			count:      *drillConfusions,
+			system:     system,
+		}
+	}
+
+	var events eventWriter
+	if !*noSave {
+		if *logPath == "" {
+			*logPath, err = eventLogPath(prof.Name)
+			if err != nil {
+				return err
+			}
+		}
+		if events, err = openEventLog(*logPath); err != nil {
+			return err
+		}
+	}
+
+	config := sessionConfig{
+		Profile:       prof,
+		Values:        values,
+		Meanings:      meanings,
+		Scheduler:     scheduler,
+		SchedulerName: *schedulerName,
+		System:        system,
+		Mode:          *mode,
+		Choices:       *choices,
+		Direction:     *direction,
+		NormalizeKana: kanaNormalizer(*includeHiragana, *includeKatakana),
+		Events:        events,
+		Random:        random,
+		Seed:          *seed,
+		Now:           now,
+		AnswerTimeout: *answerTimeout,
+		NoSave:        *noSave,
+	}
+	if *progressive {
+		config.Progress = progress
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeLimit)
+		defer cancel()
+	}
+	frontend := newTerminalFrontend(input, os.Stdout, *results, *gridColor)
+	frontend.echo = scripted
+	frontend.noTimes = scripted
+	frontend.countdown = !scripted && isTerminal(os.Stdout)
+	return drive(ctx, NewSession(config), frontend)
+}
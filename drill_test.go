@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+// captureStdout returns what a function writes to stdout.
+func captureStdout(t *testing.T, action func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		buffer := new(bytes.Buffer)
+		_, _ = io.Copy(buffer, reader)
+		output <- buffer.String()
+	}()
+	action()
+	writer.Close()
+	return <-output
+}
+
+func Test_runDrill_empty(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// the extended rows are katakana only, so there's nothing to quiz.
+	cmd, _ := findCommand("drill")
+	err := runDrill(newFlagSet(cmd), []string{"--rows=extended", "--katakana=false"})
+	assert.NotNil(err)
+	assert.Equal("no kana match the selected rows and scripts", err.Error())
+}
+
+func Test_runDrill_script(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	script := filepath.Join(dir, "answers.txt")
+	assert.Nil(os.WriteFile(script, []byte("a\nka\ni\nke\no\nsu\n"), 0644))
+
+	cmd, _ := findCommand("drill")
+	run := func() string {
+		var err error
+		output := captureStdout(t, func() {
+			err = runDrill(newFlagSet(cmd), []string{"--rows=a,ka", "--seed=7", "--no-save", "--script=" + script})
+		})
+		assert.Nil(err)
+		return output
+	}
+	first := run()
+	assert.Contains(first, "? a\n", "scripted answers should be echoed")
+	assert.NotContains(first, "Total times", "answer times shouldn't be shown")
+	assert.NotContains(first, "P95")
+	assert.Contains(first, "Seed: 7 (use --seed=7 --no-save with the same flags to replay this session)")
+	assert.Equal(first, run(), "the same script and seed should print the same thing")
+
+	exists, err := profileExists(profileDefault)
+	assert.Nil(err)
+	assert.False(exists, "the profile shouldn't be saved")
+	logPath, err := eventLogPath(profileDefault)
+	assert.Nil(err)
+	_, err = os.Stat(logPath)
+	assert.True(os.IsNotExist(err), "answers shouldn't be logged")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// event is a single answered prompt, as written to the event log.
+type event struct {
+	Timestamp    time.Time     `json:"timestamp"`
+	Session      string        `json:"session"`
+	Profile      string        `json:"profile"`
+	Kana         string        `json:"kana"`
+	Script       string        `json:"script"`
+	Mode         string        `json:"mode"`
+	Direction    string        `json:"direction"`
+	Scheduler    string        `json:"scheduler"`
+	Expected     string        `json:"expected"`
+	Answer       string        `json:"answer"`
+	Correct      bool          `json:"correct"`
+	TimedOut     bool          `json:"timedOut,omitempty"`
+	Latency      time.Duration `json:"latency"`
+	WeightBefore float64       `json:"weightBefore"`
+	WeightAfter  float64       `json:"weightAfter"`
+}
+
+// eventLog appends events to a file as JSON lines.
+type eventLog struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// eventLogPath returns the default event log path for a profile.
+func eventLogPath(name string) (string, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), name+".log.jsonl"), nil
+}
+
+// openEventLog opens an event log for appending, creating it if it doesn't exist.
+func openEventLog(path string) (*eventLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLog{file: file, encoder: json.NewEncoder(file)}, nil
+}
+
+// Write appends an event to the log.
+func (el *eventLog) Write(e event) error {
+	return el.encoder.Encode(e)
+}
+
+// Close closes the log.
+func (el *eventLog) Close() error {
+	return el.file.Close()
+}
+
+// readEvents reads every event in a log, calling a handler for each.
+func readEvents(r io.Reader, handler func(event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return err
+		}
+		if err := handler(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
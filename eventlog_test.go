@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func Test_eventLogPath(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", "/tmp/data")
+
+	path, err := eventLogPath("default")
+	assert.Nil(err)
+	assert.Equal("/tmp/data/kana/default.log.jsonl", path)
+}
+
+func Test_eventLog(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "kana", "test.log.jsonl")
+	for x := 0; x < 2; x++ {
+		events, err := openEventLog(path)
+		assert.Nil(err)
+		assert.Nil(events.Write(event{
+			Kana:     "ぬ",
+			Script:   scriptName("ぬ"),
+			Expected: "nu",
+			Answer:   "me",
+			Latency:  time.Second,
+		}))
+		assert.Nil(events.Close())
+	}
+
+	contents, err := os.ReadFile(path)
+	assert.Nil(err)
+
+	var read []event
+	assert.Nil(readEvents(bytes.NewReader(contents), func(e event) error {
+		read = append(read, e)
+		return nil
+	}))
+	assert.Len(read, 2, "the log should be appended to")
+	assert.Equal("hiragana", read[0].Script)
+	assert.Equal("me", read[0].Answer)
+	assert.Equal(time.Second, read[1].Latency)
+}
+
+func Test_scriptName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("hiragana", scriptName("ねこ"))
+	assert.Equal("katakana", scriptName("コーヒー"))
+	assert.Equal("mixed", scriptName("ねコ"))
+	assert.Equal("", scriptName("neko"))
+}
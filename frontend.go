@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// countdownInterval is how often the time left to answer is redrawn.
+const countdownInterval = 100 * time.Millisecond
+
+// Frontend presents a session to a learner, e.g. in a terminal.
+type Frontend interface {
+	// Ask shows a prompt and returns the learner's answer.
+	//
+	// It returns io.EOF if there's no more input, or ctx's error if ctx
+	// is done before the learner answers.
+	Ask(ctx context.Context, prompt Prompt) (string, error)
+	// Show shows the result of an answer.
+	Show(result Result) error
+	// Finish shows the summary at the end of a session.
+	Finish(summary Summary) error
+}
+
+// isQuit returns if an answer asks to end the session.
+func isQuit(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "q", "quit":
+		return true
+	default:
+		return false
+	}
+}
+
+// drive runs a session with a frontend until the learner quits, the input
+// ends or ctx is done (e.g. on ctrl-c or a time limit).
+//
+// However the session ends the summary is shown and the session is closed,
+// saving the profile. The session is only ever used from the calling
+// goroutine, so there's nothing to synchronize.
+func drive(ctx context.Context, s *Session, frontend Frontend) (err error) {
+	defer func() {
+		if closeErr := s.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if err = quiz(ctx, s, frontend); err != nil {
+		return err
+	}
+	return frontend.Finish(s.Summary())
+}
+
+// quiz asks prompts until the learner quits, the input ends or ctx is done.
+//
+// A prompt that isn't answered within the session's answer timeout is
+// marked incorrect and the next prompt is asked.
+func quiz(ctx context.Context, s *Session, frontend Frontend) error {
+	for ctx.Err() == nil {
+		answer, err := ask(ctx, frontend, s.Next(), s.config.AnswerTimeout)
+		var result Result
+		switch {
+		case err == io.EOF || ctx.Err() != nil:
+			return nil
+		case errors.Is(err, context.DeadlineExceeded):
+			result, err = s.TimeOut()
+		case err != nil:
+			return err
+		case isQuit(answer):
+			return nil
+		default:
+			result, err = s.Answer(answer)
+		}
+		if err != nil {
+			return err
+		}
+		if err = frontend.Show(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ask asks a prompt, giving up after a timeout if there is one.
+func ask(ctx context.Context, frontend Frontend, prompt Prompt, timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return frontend.Ask(ctx, prompt)
+}
+
+// terminalFrontend is a line based frontend for a terminal.
+type terminalFrontend struct {
+	in        io.Reader
+	lines     chan string
+	err       error
+	out       io.Writer
+	results   string
+	gridColor string
+	// echo prints each answer after its prompt, for when the answers are
+	// scripted rather than typed.
+	echo bool
+	// noTimes leaves answer times out of the summary, so a scripted
+	// session prints the same thing every time it's run.
+	noTimes bool
+	// countdown redraws the time left to answer as it runs down, rather
+	// than just showing it when the prompt is shown.
+	countdown bool
+}
+
+// newTerminalFrontend returns a terminal frontend reading answers a line at a time.
+//
+// Lines are read in the background so a prompt can be abandoned when the
+// session ends while waiting for an answer.
+func newTerminalFrontend(in io.Reader, out io.Writer, results, gridColor string) *terminalFrontend {
+	tf := &terminalFrontend{
+		in:        in,
+		lines:     make(chan string),
+		out:       out,
+		results:   results,
+		gridColor: gridColor,
+	}
+	go tf.read(in)
+	return tf
+}
+
+// read sends each line of the input to the lines channel, closing it
+// once the input ends.
+func (tf *terminalFrontend) read(in io.Reader) {
+	defer close(tf.lines)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		tf.lines <- scanner.Text()
+	}
+	tf.err = scanner.Err()
+}
+
+// Ask implements Frontend.
+//
+// If ctx has a deadline the time left is shown before the prompt.
+func (tf *terminalFrontend) Ask(ctx context.Context, prompt Prompt) (string, error) {
+	deadline, hasDeadline := ctx.Deadline()
+	var width, shown int
+	var tick <-chan time.Time
+	if hasDeadline {
+		shown = secondsLeft(deadline)
+		width = len(strconv.Itoa(shown))
+		fmt.Fprint(tf.out, formatCountdown(deadline, width))
+		if tf.countdown {
+			ticker := time.NewTicker(countdownInterval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+	}
+	fmt.Fprintf(tf.out, "%s? ", prompt.Question)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				fmt.Fprintln(tf.out)
+				tf.discardInput()
+			}
+			return "", ctx.Err()
+		case <-tick:
+			if left := secondsLeft(deadline); left != shown {
+				// save the cursor, redraw the countdown at the start of the
+				// line and restore the cursor so typing isn't interrupted.
+				shown = left
+				fmt.Fprintf(tf.out, "\0337\r%s\0338", formatCountdown(deadline, width))
+			}
+		case line, ok := <-tf.lines:
+			if !ok {
+				if tf.err != nil {
+					return "", tf.err
+				}
+				return "", io.EOF
+			}
+			if tf.echo {
+				fmt.Fprintln(tf.out, line)
+			}
+			return strings.TrimSpace(line), nil
+		}
+	}
+}
+
+// discardInput drops input for a prompt that's been abandoned, so it isn't
+// read as the answer to the next prompt.
+func (tf *terminalFrontend) discardInput() {
+	if f, ok := tf.in.(*os.File); ok && isTerminal(f) {
+		flushInput(f)
+	}
+	select {
+	case <-tf.lines:
+	default:
+	}
+}
+
+// secondsLeft returns the whole seconds left until a deadline, rounded up.
+func secondsLeft(deadline time.Time) int {
+	return max(int(math.Ceil(time.Until(deadline).Seconds())), 0)
+}
+
+// formatCountdown formats the time left until a deadline, padding the
+// seconds to a fixed width so it can be redrawn in place.
+func formatCountdown(deadline time.Time, width int) string {
+	return fmt.Sprintf("[%*ds] ", width, secondsLeft(deadline))
+}
+
+// isTerminal returns if a file is a terminal rather than e.g. a pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Show implements Frontend.
+func (tf *terminalFrontend) Show(result Result) error {
+	switch {
+	case result.TimedOut:
+		fmt.Fprintf(tf.out, "(%d/%d) out of time (%s)!\n", result.CorrectTotal, result.Answered, result.Correction)
+	case result.Correct:
+		fmt.Fprintf(tf.out, "(%d/%d) correct!\n", result.CorrectTotal, result.Answered)
+	default:
+		fmt.Fprintf(tf.out, "(%d/%d) incorrect (%s)!\n", result.CorrectTotal, result.Answered, result.Correction)
+	}
+	if result.Unlocked != "" {
+		fmt.Fprintf(tf.out, "new row unlocked: %s\n", result.Unlocked)
+	}
+	return nil
+}
+
+// Finish implements Frontend.
+func (tf *terminalFrontend) Finish(summary Summary) error {
+	fmt.Fprintln(tf.out)
+	fmt.Fprintln(tf.out, "Complete!")
+	if summary.NoSave {
+		fmt.Fprintf(tf.out, "Seed: %d (use --seed=%d --no-save with the same flags to replay this session)\n", summary.Seed, summary.Seed)
+	} else {
+		fmt.Fprintf(tf.out, "Seed: %d (use --seed=%d with the same flags to replay this session, starting from the profile as it was before it)\n", summary.Seed, summary.Seed)
+	}
+	if summary.Answered == 0 {
+		return nil
+	}
+	if summary.Correct > 0 {
+		fmt.Fprintf(tf.out, "Total score: %d/%d (%.2f%%)\n", summary.Correct, summary.Answered, (float64(summary.Correct)/float64(summary.Answered))*100)
+	} else {
+		fmt.Fprintf(tf.out, "Total score: 0/%d 0.0%%\n", summary.Answered)
+	}
+	kanaTimes := summary.KanaTimes
+	if tf.noTimes {
+		kanaTimes = nil
+	} else {
+		fmt.Fprintf(tf.out, "Total times: p95 %v, p50: %v\n", percentileOfDuration(summary.Times, 95.0).Round(time.Millisecond), percentileOfDuration(summary.Times, 50.0).Round(time.Millisecond))
+	}
+	if tf.results != resultsGrid {
+		if err := printResults(tf.out, summary.Total, summary.Incorrect, summary.Values, summary.Scheduler, kanaTimes); err != nil {
+			return err
+		}
+	}
+	if tf.results != resultsTable {
+		if err := printGrid(tf.out, summary.Total, summary.Incorrect, summary.Values, summary.Scheduler, tf.gridColor); err != nil {
+			return err
+		}
+	}
+	printConfusions(tf.out, summary.Confusions)
+	return nil
+}
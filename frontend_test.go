@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+// scriptedFrontend answers prompts from a list.
+type scriptedFrontend struct {
+	answers []string
+	// an empty answer waits for ctx to be done instead of answering.
+	// cancel, if set, is called instead of answering once the answers run out.
+	cancel  context.CancelFunc
+	prompts []Prompt
+	results []Result
+	summary *Summary
+}
+
+func (sf *scriptedFrontend) Ask(ctx context.Context, prompt Prompt) (string, error) {
+	sf.prompts = append(sf.prompts, prompt)
+	if len(sf.answers) == 0 && sf.cancel != nil {
+		sf.cancel()
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if len(sf.answers) == 0 {
+		return "", io.EOF
+	}
+	answer := sf.answers[0]
+	sf.answers = sf.answers[1:]
+	if answer == "" {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return answer, nil
+}
+
+func (sf *scriptedFrontend) Show(result Result) error {
+	sf.results = append(sf.results, result)
+	return nil
+}
+
+func (sf *scriptedFrontend) Finish(summary Summary) error {
+	sf.summary = &summary
+	return nil
+}
+
+func Test_drive(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	s, _, events := newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	frontend := &scriptedFrontend{answers: []string{"nu", "su", "q", "nu"}}
+	assert.Nil(drive(context.Background(), s, frontend))
+	assert.Len(frontend.prompts, 3)
+	assert.Len(frontend.results, 2)
+	assert.NotNil(frontend.summary)
+	assert.Equal(2, frontend.summary.Answered)
+	assert.Equal(1, frontend.summary.Correct)
+	assert.Len(frontend.answers, 1, "answers after quitting shouldn't be read")
+	assert.True(events.closed)
+
+	saved, err := loadProfile("test")
+	assert.Nil(err)
+	assert.Equal(2, saved.Total["ぬ"])
+}
+
+func Test_drive_ends(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	// the input ending finishes the session like quitting does.
+	s, _, events := newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	frontend := &scriptedFrontend{answers: []string{"nu"}}
+	assert.Nil(drive(context.Background(), s, frontend))
+	assert.NotNil(frontend.summary)
+	assert.Equal(1, frontend.summary.Answered)
+	assert.True(events.closed)
+
+	// as does cancelling while waiting for an answer.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, _, events = newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	frontend = &scriptedFrontend{answers: []string{"nu", "su"}, cancel: cancel}
+	assert.Nil(drive(ctx, s, frontend))
+	assert.Len(frontend.prompts, 3)
+	assert.NotNil(frontend.summary)
+	assert.Equal(2, frontend.summary.Answered)
+	assert.True(events.closed)
+
+	// and a context that's already done doesn't ask anything.
+	s, _, _ = newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	frontend = &scriptedFrontend{answers: []string{"nu"}}
+	assert.Nil(drive(ctx, s, frontend))
+	assert.Empty(frontend.prompts)
+	assert.NotNil(frontend.summary)
+}
+
+func Test_drive_answerTimeout(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	s, p, events := newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	s.config.AnswerTimeout = 10 * time.Millisecond
+	frontend := &scriptedFrontend{answers: []string{"", "nu", "q"}}
+	assert.Nil(drive(context.Background(), s, frontend))
+	assert.Len(frontend.results, 2)
+	assert.True(frontend.results[0].TimedOut)
+	assert.False(frontend.results[0].Correct)
+	assert.False(frontend.results[1].TimedOut)
+	assert.True(frontend.results[1].Correct)
+	assert.Equal(1, p.Incorrect["ぬ"])
+	assert.True(events.events[0].TimedOut)
+
+	// a session time limit ends the session rather than timing out the prompt.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	s, _, _ = newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	s.config.AnswerTimeout = time.Minute
+	frontend = &scriptedFrontend{answers: []string{"nu", ""}}
+	assert.Nil(drive(ctx, s, frontend))
+	assert.Len(frontend.results, 1)
+	assert.NotNil(frontend.summary)
+}
+
+func Test_isQuit(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(isQuit("q"))
+	assert.True(isQuit(" Quit "))
+	assert.False(isQuit("qu"))
+}
+
+func Test_terminalFrontend(t *testing.T) {
+	assert := assert.New(t)
+
+	out := new(bytes.Buffer)
+	frontend := newTerminalFrontend(strings.NewReader(" nu \n"), out, resultsTable, gridColorAccuracy)
+
+	answer, err := frontend.Ask(context.Background(), Prompt{Question: "ぬ"})
+	assert.Nil(err)
+	assert.Equal("nu", answer)
+	_, err = frontend.Ask(context.Background(), Prompt{Question: "す"})
+	assert.Equal(io.EOF, err)
+	assert.Equal("ぬ? す? ", out.String())
+
+	// scripted answers are echoed after the prompt.
+	out.Reset()
+	scripted := newTerminalFrontend(strings.NewReader("nu\n"), out, resultsTable, gridColorAccuracy)
+	scripted.echo = true
+	answer, err = scripted.Ask(context.Background(), Prompt{Question: "ぬ"})
+	assert.Nil(err)
+	assert.Equal("nu", answer)
+	assert.Equal("ぬ? nu\n", out.String())
+
+	// a prompt waiting for input is abandoned when the context is done.
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = newTerminalFrontend(reader, io.Discard, resultsTable, gridColorAccuracy).Ask(ctx, Prompt{Question: "ぬ"})
+	assert.Equal(context.Canceled, err)
+
+	// as is a prompt that runs out of time, which shows the time left.
+	out.Reset()
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = newTerminalFrontend(reader, out, resultsTable, gridColorAccuracy).Ask(ctx, Prompt{Question: "ぬ"})
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Equal("[1s] ぬ? \n", out.String())
+
+	out.Reset()
+	assert.Nil(frontend.Show(Result{TimedOut: true, Correction: "nu", Answered: 3, CorrectTotal: 1}))
+	assert.Equal("(1/3) out of time (nu)!\n", out.String())
+
+	out.Reset()
+	assert.Nil(frontend.Show(Result{Correct: false, Correction: "su", Answered: 2, CorrectTotal: 1, Unlocked: "ka"}))
+	assert.Equal("(1/2) incorrect (su)!\nnew row unlocked: ka\n", out.String())
+
+	out.Reset()
+	s, _, _ := newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+	s.Next()
+	_, err = s.Answer("nu")
+	assert.Nil(err)
+	assert.Nil(frontend.Finish(s.Summary()))
+	assert.Contains(out.String(), "Seed: 1 (use --seed=1 with the same flags to replay this session, starting from the profile as it was before it)")
+	assert.Contains(out.String(), "Total score: 1/1 (100.00%)")
+	assert.Contains(out.String(), "Results:")
+	assert.NotContains(out.String(), "Results (hiragana):", "only the table should be shown")
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/blend/go-sdk/ansi"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// Result formats.
+const (
+	resultsTable = "table"
+	resultsGrid  = "grid"
+	resultsBoth  = "both"
+)
+
+// Grid colorings.
+const (
+	gridColorAccuracy = "accuracy"
+	gridColorWeight   = "weight"
+)
+
+const (
+	accuracyGood = 0.9
+	accuracyFair = 0.7
+)
+
+// gridColumns are the vowel columns of the chart.
+var gridColumns = [5]string{"a", "i", "u", "e", "o"}
+
+// gridCell is the text and color of a kana in a chart grid; cells without
+// a color are written plain.
+type gridCell struct {
+	Text  string
+	Color ansi.Color
+}
+
+// validateResults validates the results format and grid coloring.
+func validateResults(results, colorBy string) error {
+	switch results {
+	case resultsTable, resultsGrid, resultsBoth:
+	default:
+		return fmt.Errorf("invalid results: %q (expected one of %s, %s, %s)", results, resultsTable, resultsGrid, resultsBoth)
+	}
+	switch colorBy {
+	case gridColorAccuracy, gridColorWeight:
+		return nil
+	default:
+		return fmt.Errorf("invalid grid color: %q (expected one of %s, %s)", colorBy, gridColorAccuracy, gridColorWeight)
+	}
+}
+
+// gridRows returns the rows of the chart with any kana in a set for a script.
+func gridRows(values map[string]string, script romaji.Script) []row {
+	var output []row
+	for _, r := range rows {
+		if r.KatakanaOnly && script == romaji.ScriptHiragana {
+			continue
+		}
+		for _, kana := range r.Kana {
+			if _, ok := values[scriptKana(kana, script)]; ok && kana != "" {
+				output = append(output, r)
+				break
+			}
+		}
+	}
+	return output
+}
+
+// scriptKana returns a kana from a row in a given script.
+func scriptKana(kana string, script romaji.Script) string {
+	if script == romaji.ScriptKatakana {
+		return romaji.ToKatakana(kana)
+	}
+	return kana
+}
+
+// writeGrid writes rows of the chart as a grid with a-i-u-e-o columns,
+// with the text and color of each kana from a given function.
+//
+// Kana the function returns false for are left blank.
+func writeGrid(wr io.Writer, chart []row, script romaji.Script, cell func(kana string) (gridCell, bool)) error {
+	cells := make([][5]*gridCell, len(chart))
+	labelWidth, cellWidth := 0, 0
+	for rowIndex, r := range chart {
+		labelWidth = max(labelWidth, displayWidth(r.Name))
+		for column, kana := range r.Kana {
+			if kana == "" || (r.KatakanaOnly && script == romaji.ScriptHiragana) {
+				continue
+			}
+			if c, ok := cell(scriptKana(kana, script)); ok {
+				cells[rowIndex][column] = &c
+				cellWidth = max(cellWidth, displayWidth(c.Text))
+			}
+		}
+	}
+	cellWidth += 2
+
+	var output strings.Builder
+	output.WriteString(strings.Repeat(" ", labelWidth+1))
+	for _, column := range gridColumns {
+		output.WriteString(padDisplay(column, cellWidth))
+	}
+	output.WriteString("\n")
+	for rowIndex, r := range chart {
+		output.WriteString(padDisplay(r.Name, labelWidth+1))
+		for _, c := range cells[rowIndex] {
+			if c == nil {
+				output.WriteString(strings.Repeat(" ", cellWidth))
+				continue
+			}
+			if c.Color != "" {
+				output.WriteString(ansi.Apply(c.Color, c.Text))
+			} else {
+				output.WriteString(c.Text)
+			}
+			output.WriteString(strings.Repeat(" ", cellWidth-displayWidth(c.Text)))
+		}
+		output.WriteString("\n")
+	}
+	_, err := io.WriteString(wr, output.String())
+	return err
+}
+
+// printGrid prints the results for each script as a grid, colored by
+// accuracy or by the scheduler's weights.
+func printGrid(wr io.Writer, total, incorrect map[string]int, values map[string]string, scheduler Scheduler, colorBy string) error {
+	minWeight, maxWeight := weightRange(values, scheduler)
+
+	for _, script := range []romaji.Script{romaji.ScriptHiragana, romaji.ScriptKatakana} {
+		chart := gridRows(values, script)
+		if len(chart) == 0 {
+			continue
+		}
+		fmt.Fprintf(wr, "Results (%s):\n", scriptNames[script])
+		err := writeGrid(wr, chart, script, func(kana string) (gridCell, bool) {
+			if _, ok := values[kana]; !ok {
+				return gridCell{}, false
+			}
+			if colorBy == gridColorWeight {
+				weight := scheduler.Weight(kana)
+				return gridCell{
+					Text:  fmt.Sprintf("%s %.2f", kana, weight),
+					Color: weightColor(weight, minWeight, maxWeight),
+				}, true
+			}
+			if total[kana] == 0 {
+				return gridCell{Text: kana + " -", Color: ansi.ColorLightBlack}, true
+			}
+			value := accuracy(total[kana]-incorrect[kana], total[kana])
+			return gridCell{
+				Text:  fmt.Sprintf("%s %.0f%%", kana, value*100),
+				Color: accuracyColor(value),
+			}, true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accuracyColor returns green for good accuracy, yellow for fair, and red otherwise.
+func accuracyColor(value float64) ansi.Color {
+	switch {
+	case value >= accuracyGood:
+		return ansi.ColorGreen
+	case value >= accuracyFair:
+		return ansi.ColorYellow
+	default:
+		return ansi.ColorRed
+	}
+}
+
+// weightRange returns the lowest and highest weights of a set of kana.
+func weightRange(values map[string]string, scheduler Scheduler) (minWeight, maxWeight float64) {
+	minWeight, maxWeight = math.Inf(1), math.Inf(-1)
+	for kana := range values {
+		minWeight = math.Min(minWeight, scheduler.Weight(kana))
+		maxWeight = math.Max(maxWeight, scheduler.Weight(kana))
+	}
+	return
+}
+
+// weightColor returns a color for a weight relative to the lowest and highest
+// weights shown, from green for the lowest to red for the highest.
+//
+// Weights are compared on a log scale as the schedulers scale them by factors.
+func weightColor(weight, minWeight, maxWeight float64) ansi.Color {
+	if maxWeight <= minWeight || weight <= 0 || minWeight <= 0 {
+		return ansi.ColorYellow
+	}
+	relative := math.Log(weight/minWeight) / math.Log(maxWeight/minWeight)
+	switch {
+	case relative < 1.0/3.0:
+		return ansi.ColorGreen
+	case relative < 2.0/3.0:
+		return ansi.ColorYellow
+	default:
+		return ansi.ColorRed
+	}
+}
+
+// displayWidth returns the number of terminal columns a string takes up,
+// counting full-width characters (e.g. kana) as two columns.
+func displayWidth(value string) (width int) {
+	for _, r := range value {
+		if r >= 0x1100 && !(r >= halfWidthStart && r <= halfWidthHandaku) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return
+}
+
+// padDisplay pads a string with spaces to a given display width.
+func padDisplay(value string, width int) string {
+	if pad := width - displayWidth(value); pad > 0 {
+		return value + strings.Repeat(" ", pad)
+	}
+	return value
+}
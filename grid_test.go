@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/ansi"
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_validateResults(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(validateResults(resultsGrid, gridColorWeight))
+	assert.NotNil(validateResults("list", gridColorAccuracy))
+	assert.NotNil(validateResults(resultsTable, "latency"))
+}
+
+func Test_gridRows(t *testing.T) {
+	assert := assert.New(t)
+
+	values := map[string]string{"カ": "ka", "ファ": "fa", "あ": "a"}
+	katakana := gridRows(values, romaji.ScriptKatakana)
+	assert.Len(katakana, 2)
+	assert.Equal("ka", katakana[0].Name)
+	assert.Equal("fa", katakana[1].Name)
+
+	hiragana := gridRows(values, romaji.ScriptHiragana)
+	assert.Len(hiragana, 1)
+	assert.Equal("a", hiragana[0].Name)
+}
+
+func Test_writeGrid(t *testing.T) {
+	assert := assert.New(t)
+
+	chart := []row{rows[0], rows[7]}
+	buffer := new(bytes.Buffer)
+	assert.Nil(writeGrid(buffer, chart, romaji.ScriptHiragana, func(kana string) (gridCell, bool) {
+		return gridCell{Text: kana, Color: ansi.ColorGreen}, kana != "い"
+	}))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	assert.Len(lines, 3)
+	assert.Equal("   a   i   u   e   o   ", lines[0])
+	green := func(kana string) string { return ansi.Apply(ansi.ColorGreen, kana) }
+	assert.Equal("a  "+green("あ")+"      "+green("う")+"  "+green("え")+"  "+green("お")+"  ", lines[1], "kana left out should be blank")
+	assert.True(strings.HasPrefix(lines[2], "ya "), "rows should be labelled")
+}
+
+func Test_printGrid(t *testing.T) {
+	assert := assert.New(t)
+
+	values := map[string]string{"あ": "a", "い": "i", "う": "u"}
+	p := newProfile("test")
+	p.init(values)
+	scheduler, err := newScheduler(schedulerWeighted, values, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.Nil(err)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(printGrid(buffer, map[string]int{"あ": 10, "い": 4}, map[string]int{"い": 2}, values, scheduler, gridColorAccuracy))
+	assert.Contains(buffer.String(), "Results (hiragana):")
+	assert.Contains(buffer.String(), ansi.Apply(ansi.ColorGreen, "あ 100%"))
+	assert.Contains(buffer.String(), ansi.Apply(ansi.ColorRed, "い 50%"))
+	assert.Contains(buffer.String(), ansi.Apply(ansi.ColorLightBlack, "う -"))
+	assert.NotContains(buffer.String(), "katakana")
+}
+
+func Test_weightColor(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(ansi.ColorGreen, weightColor(0.0625, 0.0625, 512))
+	assert.Equal(ansi.ColorYellow, weightColor(4, 0.0625, 512))
+	assert.Equal(ansi.ColorRed, weightColor(512, 0.0625, 512))
+	assert.Equal(ansi.ColorYellow, weightColor(1, 1, 1))
+}
+
+func Test_displayWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(2, displayWidth("ka"))
+	assert.Equal(4, displayWidth("きゃ"))
+	assert.Equal(2, displayWidth("ｶﾞ"))
+	assert.Equal("か  ", padDisplay("か", 4))
+}
@@ -2,16 +2,13 @@ package main
 
 import (
 	"bufio"
-	"errors"
-	"flag"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"os"
-	"os/signal"
 	"sort"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/blend/go-sdk/ansi"
@@ -27,254 +24,9 @@ const (
 )
 
 func main() {
-	includeKatakana := flagBoolP("katakana", "k", true, "If we should quiz katakana")
-	includeHiragana := flagBoolP("hiragana", "h", true, "If we should quiz hiragana")
-	limit := flagIntP("limit", "l", 0, "A limit for the number of kana to test")
-	flag.Parse()
-
-	var totalAnswered, totalCorrect int
-	var times []time.Duration
-
-	var sets []map[string]string
-	if *includeKatakana {
-		sets = append(sets, katakana)
-	}
-	if *includeHiragana {
-		sets = append(sets, hiragana)
-	}
-	values := mergeSets(sets...)
-
-	if *limit > 0 {
-		values = selectCount(values, *limit)
-	}
-
-	weights := createWeights(values)
-	total := make(map[string]int)
-	incorrect := make(map[string]int)
-	kanaTimes := make(map[string][]time.Duration)
-
-	finish := func() {
-		fmt.Println()
-		fmt.Println("Complete!")
-		if totalAnswered > 0 {
-			if totalCorrect > 0 {
-				fmt.Printf("Total score: %d/%d (%.2f%%)\n", totalCorrect, totalAnswered, (float64(totalCorrect)/float64(totalAnswered))*100)
-			} else {
-				fmt.Printf("Total score: 0/%d 0.0%%\n", totalAnswered)
-			}
-			fmt.Printf("Total times: p95 %v, p50: %v\n", percentileOfDuration(times, 95.0).Round(time.Millisecond), percentileOfDuration(times, 50.0).Round(time.Millisecond))
-			printResults(total, incorrect, values, weights, kanaTimes)
-		}
-		os.Exit(0)
-	}
-
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				fatal(fmt.Errorf("%v", r))
-			}
-		}()
-
-		var history []string
-		var kana, roman string
-		var start time.Time
-		var elapsed time.Duration
-		var isCorrect bool
-		var err error
-
-		effectiveMaxRepeatHistory := maxRepeatHistory
-		if maxRepeatHistory >= len(values) {
-			effectiveMaxRepeatHistory = len(values) >> 1
-		}
-
-		for {
-			kana, roman = selectWeighted(values, weights)
-
-			if listHas(history, kana) {
-				continue
-			}
-			history = listAddFixedLength(history, kana, effectiveMaxRepeatHistory)
-
-			start = time.Now()
-
-			if isCorrect, err = ask(kana, roman); err != nil {
-				if err == errQuit {
-					finish()
-				}
-			} else if isCorrect {
-				totalAnswered++
-				incrementCount(total, kana)
-				decreaseWeight(weights, kana)
-				totalCorrect++
-				fmt.Printf("(%d/%d) correct!\n", totalCorrect, totalAnswered)
-			} else {
-				totalAnswered++
-				incrementCount(total, kana)
-				increaseWeight(weights, kana)
-				incrementCount(incorrect, kana)
-				fmt.Printf("(%d/%d) incorrect (%s)!\n", totalCorrect, totalAnswered, roman)
-			}
-
-			elapsed = time.Since(start)
-
-			kanaTimes[kana] = append(kanaTimes[kana], elapsed)
-			times = append(times, elapsed)
-		}
-	}()
-
-	waitSigInt()
-	finish()
-}
-
-var katakana = map[string]string{
-	"ア": "a",
-	"イ": "i",
-	"ウ": "u",
-	"エ": "e",
-	"オ": "o",
-	"カ": "ka",
-	"キ": "ki",
-	"ク": "ku",
-	"ケ": "ke",
-	"コ": "ko",
-	"サ": "sa",
-	"シ": "shi",
-	"ス": "su",
-	"セ": "se",
-	"ソ": "so",
-	"ナ": "na",
-	"ニ": "ni",
-	"ヌ": "nu",
-	"ネ": "ne",
-	"ノ": "no",
-	"ハ": "ha",
-	"ヒ": "hi",
-	"フ": "fu",
-	"ヘ": "he",
-	"ホ": "ho",
-	"マ": "ma",
-	"ミ": "mi",
-	"ム": "mu",
-	"メ": "me",
-	"モ": "mo",
-	"ラ": "ra",
-	"リ": "ri",
-	"ル": "ru",
-	"レ": "re",
-	"ロ": "ro",
-	"ワ": "wa",
-	"ヲ": "wo",
-	"ヤ": "ya",
-	"ユ": "yu",
-	"ヨ": "yo",
-	"タ": "ta",
-	"チ": "chi",
-	"ツ": "tsu",
-	"テ": "te",
-	"ト": "to",
-	"ン": "n",
-	"ガ": "ga",
-	"ギ": "gi",
-	"グ": "gu",
-	"ゲ": "ge",
-	"ゴ": "go",
-	"ザ": "za",
-	"ジ": "zi",
-	"ズ": "zu",
-	"ゼ": "ze",
-	"ゾ": "zo",
-	"ヂ": "di",
-	"ヅ": "du",
-	"デ": "de",
-	"ド": "do",
-	"バ": "ba",
-	"ビ": "bi",
-	"ブ": "bu",
-	"ベ": "be",
-	"ボ": "bo",
-	"パ": "pa",
-	"ピ": "pi",
-	"プ": "pu",
-	"ペ": "pe",
-	"ポ": "po",
-}
-
-var hiragana = map[string]string{
-	"あ": "a",
-	"い": "i",
-	"う": "u",
-	"え": "e",
-	"お": "o",
-	"か": "ka",
-	"き": "ki",
-	"く": "ku",
-	"け": "ke",
-	"こ": "ko",
-	"さ": "sa",
-	"し": "shi",
-	"す": "su",
-	"せ": "se",
-	"そ": "so",
-	"た": "ta",
-	"ち": "chi",
-	"つ": "tsu",
-	"て": "te",
-	"と": "to",
-	"な": "na",
-	"に": "ni",
-	"ぬ": "nu",
-	"ね": "ne",
-	"の": "no",
-	"は": "ha",
-	"ひ": "hi",
-	"ふ": "fu",
-	"へ": "he",
-	"ほ": "ho",
-	"ま": "ma",
-	"み": "mi",
-	"む": "mu",
-	"め": "me",
-	"も": "mo",
-	"や": "ya",
-	"ゆ": "yu",
-	"よ": "yo",
-	"ら": "ra",
-	"り": "ri",
-	"る": "ru",
-	"れ": "re",
-	"ろ": "ro",
-	"わ": "wa",
-	"を": "wo",
-	"ん": "n",
-	"が": "ga",
-	"ぎ": "gi",
-	"ぐ": "gu",
-	"げ": "ge",
-	"ご": "go",
-	"ざ": "za",
-	"じ": "ji",
-	"ず": "zu",
-	"ぜ": "ze",
-	"ぞ": "zo",
-	"だ": "da",
-	"ぢ": "di",
-	"づ": "du",
-	"で": "de",
-	"ど": "do",
-	"ば": "ba",
-	"び": "bi",
-	"ぶ": "bu",
-	"べ": "be",
-	"ぼ": "bo",
-	"ぱ": "pa",
-	"ぴ": "pi",
-	"ぷ": "pu",
-	"ぺ": "pe",
-	"ぽ": "po",
+	fatal(runCommand(os.Args[1:]))
 }
 
-var errQuit = errors.New("should quit")
-
 func promptf(format string, args ...interface{}) string {
 	fmt.Fprintf(os.Stdout, format, args...)
 	scanner := bufio.NewScanner(os.Stdin)
@@ -285,19 +37,6 @@ func promptf(format string, args ...interface{}) string {
 	return output
 }
 
-func ask(question, expected string) (bool, error) {
-	actual := promptf("%s? ", question)
-	switch strings.ToLower(strings.TrimSpace(actual)) {
-	case "quit", "q":
-		return false, errQuit
-
-	}
-	if strings.ToLower(actual) == strings.ToLower(expected) {
-		return true, nil
-	}
-	return false, nil
-}
-
 func createWeights(values map[string]string) map[string]float64 {
 	output := make(map[string]float64)
 	for key := range values {
@@ -323,7 +62,7 @@ func decreaseWeight(weights map[string]float64, value string) {
 	}
 }
 
-func selectWeighted(values map[string]string, weights map[string]float64) (kana, roman string) {
+func selectWeighted(random *rand.Rand, values map[string]string, weights map[string]float64) (kana, roman string) {
 	// collect "weighted" choices
 	type weightedChoice struct {
 		Key    string
@@ -337,9 +76,13 @@ func selectWeighted(values map[string]string, weights map[string]float64) (kana,
 		})
 	}
 
-	// sort by weight ascending
+	// sort by weight ascending, then in chart order so ties don't depend
+	// on the map's iteration order
 	sort.Slice(keys, func(i, j int) bool {
-		return keys[i].Weight < keys[j].Weight
+		if keys[i].Weight != keys[j].Weight {
+			return keys[i].Weight < keys[j].Weight
+		}
+		return kanaLess(keys[i].Key, keys[j].Key)
 	})
 
 	// sum all the weights, assign to indexes
@@ -349,7 +92,7 @@ func selectWeighted(values map[string]string, weights map[string]float64) (kana,
 		runningTotal += wc.Weight
 		totals[index] = runningTotal
 	}
-	randomValue := rand.Float64() * runningTotal
+	randomValue := random.Float64() * runningTotal
 	randomIndex := sort.SearchFloat64s(totals, randomValue)
 
 	kana = keys[randomIndex].Key
@@ -367,20 +110,6 @@ func mergeSets(sets ...map[string]string) map[string]string {
 	return output
 }
 
-func flagBoolP(long, short string, defaultValue bool, usage string) *bool {
-	var value bool
-	flag.BoolVar(&value, long, defaultValue, usage)
-	flag.BoolVar(&value, short, defaultValue, usage+" (shorthand)")
-	return &value
-}
-
-func flagIntP(long, short string, defaultValue int, usage string) *int {
-	var value int
-	flag.IntVar(&value, long, defaultValue, usage)
-	flag.IntVar(&value, short, defaultValue, usage+" (shorthand)")
-	return &value
-}
-
 func incrementCount(values map[string]int, key string) {
 	if count, ok := values[key]; !ok {
 		values[key] = 1
@@ -389,9 +118,11 @@ func incrementCount(values map[string]int, key string) {
 	}
 }
 
-func printResults(total, incorrect map[string]int, values map[string]string, weights map[string]float64, kanaTimes map[string][]time.Duration) {
+// printResults prints a table of the results for each kana that's been
+// answered; if kanaTimes is nil the answer time columns are left out.
+func printResults(wr io.Writer, total, incorrect map[string]int, values map[string]string, scheduler Scheduler, kanaTimes map[string][]time.Duration) error {
 	if len(values) == 0 {
-		return
+		return nil
 	}
 	columns := []string{
 		"Kana (Roman)",
@@ -401,52 +132,78 @@ func printResults(total, incorrect map[string]int, values map[string]string, wei
 		"P95",
 		"P50",
 	}
+	if kanaTimes == nil {
+		columns = columns[:4]
+	}
+	keys := make([]string, 0, len(values))
+	for kana := range values {
+		keys = append(keys, kana)
+	}
+	sortKana(keys)
+
 	var rows [][]string
-	for kana, roman := range values {
+	for _, kana := range keys {
+		roman := values[kana]
 		totalCount, hasTotal := total[kana]
 		incorrectCount := incorrect[kana]
-		if hasTotal {
-			rows = append(rows, []string{
-				fmt.Sprintf("%s (%s)", kana, roman),
-				strconv.Itoa(totalCount),
-				strconv.Itoa(incorrectCount),
-				fmt.Sprintf("%.2f", weights[kana]),
+		if !hasTotal {
+			continue
+		}
+		row := []string{
+			fmt.Sprintf("%s (%s)", kana, roman),
+			strconv.Itoa(totalCount),
+			strconv.Itoa(incorrectCount),
+			fmt.Sprintf("%.2f", scheduler.Weight(kana)),
+		}
+		if kanaTimes != nil {
+			row = append(row,
 				fmt.Sprint(percentileOfDuration(kanaTimes[kana], 95.0).Round(time.Millisecond)),
 				fmt.Sprint(percentileOfDuration(kanaTimes[kana], 50.0).Round(time.Millisecond)),
-			})
+			)
 		}
+		rows = append(rows, row)
+	}
+	if kanaTimes == nil {
+		fmt.Fprintln(wr, "Results:")
+		return ansi.Table(wr, columns, rows)
 	}
-	sort.Slice(rows, func(i, j int) bool {
+	sort.SliceStable(rows, func(i, j int) bool {
 		// sort by p95
 		iv, _ := time.ParseDuration(rows[i][4])
 		jv, _ := time.ParseDuration(rows[j][4])
 		return iv > jv
 	})
 
-	fmt.Println("Results:")
-	fatal(ansi.Table(os.Stdout, columns, rows))
-}
-
-func waitSigInt() {
-	sigint := make(chan os.Signal, 1)
-	signal.Notify(sigint, os.Interrupt)
-	<-sigint
+	fmt.Fprintln(wr, "Results:")
+	return ansi.Table(wr, columns, rows)
 }
 
+// selectCount returns the first `count` values in chart order.
 func selectCount(values map[string]string, count int) map[string]string {
 	if len(values) <= count {
 		return values
 	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sortKana(keys)
+
 	output := make(map[string]string)
-	for key, value := range values {
-		output[key] = value
-		if len(output) == count {
-			break
-		}
+	for _, key := range keys[:count] {
+		output[key] = values[key]
 	}
 	return output
 }
 
+// effectiveHistory returns how many recent kana to avoid repeating for a set.
+func effectiveHistory(values map[string]string) int {
+	if maxRepeatHistory >= len(values) {
+		return len(values) >> 1
+	}
+	return maxRepeatHistory
+}
+
 // listHas returns if a value is present in a list
 func listHas(list []string, value string) bool {
 	for _, listValue := range list {
@@ -479,6 +236,19 @@ func min(values ...int) int {
 	return working
 }
 
+func max(values ...int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	working := values[0]
+	for _, value := range values[1:] {
+		if value > working {
+			working = value
+		}
+	}
+	return working
+}
+
 // percentileOfDuration finds the relative standing in a slice of durations
 func percentileOfDuration(input []time.Duration, percentile float64) time.Duration {
 	if len(input) == 0 {
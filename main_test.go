@@ -1,18 +1,25 @@
 package main
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
 )
 
+// testRandom returns a seeded source of random choices for tests.
+func testRandom() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
 func Test_createWeights(t *testing.T) {
 	assert := assert.New(t)
 
-	weights := createWeights(katakana)
-	assert.Len(weights, len(katakana))
+	weights := createWeights(romaji.Katakana)
+	assert.Len(weights, len(romaji.Katakana))
 	for key, value := range weights {
-		assert.NotEmpty(katakana[key])
+		assert.NotEmpty(romaji.Katakana[key])
 		assert.Equal(1.0, value)
 	}
 }
@@ -20,7 +27,7 @@ func Test_createWeights(t *testing.T) {
 func Test_increaseWeight(t *testing.T) {
 	assert := assert.New(t)
 
-	weights := createWeights(katakana)
+	weights := createWeights(romaji.Katakana)
 
 	increaseWeight(weights, "ヂ")
 	assert.Equal(2.0, weights["ヂ"])
@@ -29,7 +36,7 @@ func Test_increaseWeight(t *testing.T) {
 func Test_decreaseWeight(t *testing.T) {
 	assert := assert.New(t)
 
-	weights := createWeights(katakana)
+	weights := createWeights(romaji.Katakana)
 	decreaseWeight(weights, "ヂ")
 	assert.Equal(0.5, weights["ヂ"])
 	decreaseWeight(weights, "ヂ")
@@ -58,8 +65,9 @@ func Test_selectWeighted(t *testing.T) {
 
 	counts := make(map[string]int)
 
+	random := testRandom()
 	for x := 0; x < 1024; x++ {
-		key, value := selectWeighted(values, weights)
+		key, value := selectWeighted(random, values, weights)
 		assert.Equal(values[key], value)
 		counts[key] = counts[key] + 1
 	}
@@ -69,6 +77,24 @@ func Test_selectWeighted(t *testing.T) {
 	assert.True(counts["baz"] > counts["boo"])
 }
 
+func Test_selectWeighted_seed(t *testing.T) {
+	assert := assert.New(t)
+
+	// every kana has the same weight, so only the seed decides the order.
+	values := mergeSets(romaji.Hiragana, romaji.Katakana)
+	weights := createWeights(values)
+	selectAll := func() []string {
+		random := rand.New(rand.NewSource(42))
+		var output []string
+		for x := 0; x < 32; x++ {
+			key, _ := selectWeighted(random, values, weights)
+			output = append(output, key)
+		}
+		return output
+	}
+	assert.Equal(selectAll(), selectAll())
+}
+
 func Test_selectCount(t *testing.T) {
 	assert := assert.New(t)
 
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	profileDefault  = "default"
+	maxProfileTimes = 128
+)
+
+// profile is the learner state we persist between sessions.
+type profile struct {
+	Name       string                     `json:"name"`
+	Weights    map[string]float64         `json:"weights"`
+	Total      map[string]int             `json:"total"`
+	Incorrect  map[string]int             `json:"incorrect"`
+	KanaTimes  map[string][]time.Duration `json:"kanaTimes"`
+	Cards      map[string]*card           `json:"cards"`
+	Confusions map[string]map[string]int  `json:"confusions"`
+	Unlocked   int                        `json:"unlocked,omitempty"`
+}
+
+// newProfile returns an empty profile with a given name.
+func newProfile(name string) *profile {
+	return &profile{
+		Name:       name,
+		Weights:    make(map[string]float64),
+		Total:      make(map[string]int),
+		Incorrect:  make(map[string]int),
+		KanaTimes:  make(map[string][]time.Duration),
+		Cards:      make(map[string]*card),
+		Confusions: make(map[string]map[string]int),
+	}
+}
+
+// fillMaps creates any of the profile's maps that are missing, e.g. because
+// they're null in the JSON it was read from.
+func (p *profile) fillMaps() {
+	if p.Weights == nil {
+		p.Weights = make(map[string]float64)
+	}
+	if p.Total == nil {
+		p.Total = make(map[string]int)
+	}
+	if p.Incorrect == nil {
+		p.Incorrect = make(map[string]int)
+	}
+	if p.KanaTimes == nil {
+		p.KanaTimes = make(map[string][]time.Duration)
+	}
+	if p.Cards == nil {
+		p.Cards = make(map[string]*card)
+	}
+	if p.Confusions == nil {
+		p.Confusions = make(map[string]map[string]int)
+	}
+}
+
+// init fills in default weights for any values the profile hasn't seen yet.
+func (p *profile) init(values map[string]string) {
+	for key, weight := range createWeights(values) {
+		if _, ok := p.Weights[key]; !ok {
+			p.Weights[key] = weight
+		}
+	}
+}
+
+// times returns every answer time in the profile.
+func (p *profile) times() []time.Duration {
+	var output []time.Duration
+	for _, times := range p.KanaTimes {
+		output = append(output, times...)
+	}
+	return output
+}
+
+// profileDir returns the directory profiles are stored in.
+//
+// It follows the XDG base directory spec, i.e. `$XDG_DATA_HOME/kana`
+// falling back to `~/.local/share/kana`.
+func profileDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "kana"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "kana"), nil
+}
+
+// profilePath returns the path for a given profile name.
+func profilePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid profile name: %q", name)
+	}
+	dir, err := profileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// loadProfile reads a profile from disk, returning an empty profile if
+// it hasn't been saved yet.
+func loadProfile(name string) (*profile, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return newProfile(name), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	output := newProfile(name)
+	if err = json.Unmarshal(contents, output); err != nil {
+		return nil, fmt.Errorf("reading profile %s: %v", path, err)
+	}
+	output.fillMaps()
+	output.Name = name
+	return output, nil
+}
+
+// saveProfile writes a profile to disk.
+//
+// It writes to a temporary file first so an interrupted save
+// doesn't clobber the previous profile.
+func saveProfile(p *profile) error {
+	path, err := profilePath(p.Name)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	for kana, times := range p.KanaTimes {
+		if len(times) > maxProfileTimes {
+			p.KanaTimes[kana] = times[len(times)-maxProfileTimes:]
+		}
+	}
+	contents, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	tempPath := path + ".tmp"
+	if err = os.WriteFile(tempPath, contents, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
+
+// profileExists returns if a profile has been saved.
+func profileExists(name string) (bool, error) {
+	path, err := profilePath(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// runExport implements the `export` command.
+func runExport(flags *flagSet, args []string) error {
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile to export")
+	out := flags.StringP("out", "o", "", "The file to write the profile to (defaults to stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	exists, err := profileExists(*profileName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("profile %q hasn't been saved yet", *profileName)
+	}
+	p, err := loadProfile(*profileName)
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	contents = append(contents, '\n')
+	if *out == "" {
+		_, err = os.Stdout.Write(contents)
+		return err
+	}
+	return os.WriteFile(*out, contents, 0644)
+}
+
+// runImport implements the `import` command.
+func runImport(flags *flagSet, args []string) error {
+	profileName := flags.StringP("profile", "p", "", "The profile to import as (defaults to the name in the file)")
+	force := flags.BoolP("force", "f", false, "If we should replace a profile that already exists")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import expects a single file to read (or - for stdin)")
+	}
+
+	var contents []byte
+	var err error
+	if path := flags.Arg(0); path == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	p := newProfile("")
+	if err = json.Unmarshal(contents, p); err != nil {
+		return fmt.Errorf("reading profile %s: %v", flags.Arg(0), err)
+	}
+	p.fillMaps()
+	if *profileName != "" {
+		p.Name = *profileName
+	}
+	if p.Name == "" {
+		p.Name = profileDefault
+	}
+
+	exists, err := profileExists(p.Name)
+	if err != nil {
+		return err
+	}
+	if exists && !*force {
+		return fmt.Errorf("profile %q already exists (use --force to replace it)", p.Name)
+	}
+	if err = saveProfile(p); err != nil {
+		return err
+	}
+	fmt.Printf("imported profile %q\n", p.Name)
+	return nil
+}
+
+// runReset implements the `reset` command.
+func runReset(flags *flagSet, args []string) error {
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile to reset")
+	events := flags.Bool("events", false, "If we should also delete the profile's event log")
+	yes := flags.BoolP("yes", "y", false, "If we should reset without asking to confirm")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	path, err := profilePath(*profileName)
+	if err != nil {
+		return err
+	}
+	paths := []string{path}
+	if *events {
+		logPath, err := eventLogPath(*profileName)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, logPath)
+	}
+
+	if !*yes {
+		answer := strings.ToLower(strings.TrimSpace(promptf("reset profile %q? [y/N] ", *profileName)))
+		if answer != "y" && answer != "yes" {
+			return nil
+		}
+	}
+	for _, path := range paths {
+		if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	fmt.Printf("reset profile %q\n", *profileName)
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_profilePath(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", "/tmp/data")
+
+	path, err := profilePath("default")
+	assert.Nil(err)
+	assert.Equal("/tmp/data/kana/default.json", path)
+
+	_, err = profilePath("../default")
+	assert.NotNil(err)
+	_, err = profilePath("")
+	assert.NotNil(err)
+}
+
+func Test_profile_saveLoad(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	loaded, err := loadProfile("test")
+	assert.Nil(err)
+	assert.Empty(loaded.Weights)
+
+	loaded.init(romaji.Hiragana)
+	assert.Len(loaded.Weights, len(romaji.Hiragana))
+
+	loaded.Weights["あ"] = 8.0
+	incrementCount(loaded.Total, "あ")
+	incrementCount(loaded.Incorrect, "あ")
+	loaded.KanaTimes["あ"] = []time.Duration{time.Second, 2 * time.Second}
+	assert.Nil(saveProfile(loaded))
+
+	reloaded, err := loadProfile("test")
+	assert.Nil(err)
+	assert.Equal(8.0, reloaded.Weights["あ"])
+	assert.Equal(1, reloaded.Total["あ"])
+	assert.Equal(1, reloaded.Incorrect["あ"])
+	assert.Len(reloaded.KanaTimes["あ"], 2)
+
+	reloaded.init(romaji.Hiragana)
+	assert.Equal(8.0, reloaded.Weights["あ"], "init should not reset learned weights")
+}
+
+func Test_profile_exportImportReset(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	exportCommand, _ := findCommand("export")
+	importCommand, _ := findCommand("import")
+	resetCommand, _ := findCommand("reset")
+
+	assert.NotNil(runExport(newFlagSet(exportCommand), []string{"-p", "alice"}), "unsaved profiles can't be exported")
+
+	alice := newProfile("alice")
+	alice.Weights["あ"] = 4.0
+	assert.Nil(saveProfile(alice))
+
+	exported := filepath.Join(dir, "alice.json")
+	assert.Nil(runExport(newFlagSet(exportCommand), []string{"-p", "alice", "-o", exported}))
+	assert.NotNil(runImport(newFlagSet(importCommand), []string{exported}), "existing profiles shouldn't be replaced")
+	assert.Nil(runImport(newFlagSet(importCommand), []string{"-p", "bob", exported}))
+
+	bob, err := loadProfile("bob")
+	assert.Nil(err)
+	assert.Equal("bob", bob.Name)
+	assert.Equal(4.0, bob.Weights["あ"])
+
+	exists, err := profileExists("bob")
+	assert.Nil(err)
+	assert.True(exists)
+	assert.Nil(runReset(newFlagSet(resetCommand), []string{"-p", "bob", "--yes"}))
+	exists, err = profileExists("bob")
+	assert.Nil(err)
+	assert.False(exists)
+}
+
+func Test_profile_nullMaps(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dir)
+
+	contents := []byte(`{"name":"z","weights":null,"total":null,"incorrect":null,"kanaTimes":null,"cards":null,"confusions":null}`)
+	path := filepath.Join(dir, "z.json")
+	assert.Nil(os.WriteFile(path, contents, 0644))
+
+	importCommand, _ := findCommand("import")
+	assert.Nil(runImport(newFlagSet(importCommand), []string{path}))
+	imported, err := loadProfile("z")
+	assert.Nil(err)
+	imported.init(romaji.Hiragana)
+	assert.Equal(weightDefault, imported.Weights["あ"])
+
+	// profiles saved with null maps some other way load too.
+	assert.Nil(os.MkdirAll(filepath.Join(dir, "kana"), 0755))
+	assert.Nil(os.WriteFile(filepath.Join(dir, "kana", "y.json"), contents, 0644))
+	loaded, err := loadProfile("y")
+	assert.Nil(err)
+	assert.NotNil(loaded.Weights)
+	assert.NotNil(loaded.Total)
+	assert.NotNil(loaded.Incorrect)
+	assert.NotNil(loaded.KanaTimes)
+	assert.NotNil(loaded.Cards)
+	assert.NotNil(loaded.Confusions)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	masteryLatencyDefault  = 1500 * time.Millisecond
+	sm2MasteredRepetitions = 2
+	leitnerMasteredBox     = 3
+)
+
+// progression unlocks rows one at a time as the kana in the pool are mastered.
+//
+// The number of rows unlocked is stored on the profile so it
+// carries across sessions.
+type progression struct {
+	profile         *profile
+	includeHiragana bool
+	includeKatakana bool
+	masteryLatency  time.Duration
+}
+
+// pool returns the kana in the unlocked rows.
+func (p *progression) pool() map[string]string {
+	if p.profile.Unlocked < 1 {
+		p.profile.Unlocked = 1
+	}
+	var names []string
+	for _, r := range rows[:min(p.profile.Unlocked, len(rows))] {
+		names = append(names, r.Name)
+	}
+	return rowSet(names, p.includeHiragana, p.includeKatakana)
+}
+
+// mastered returns if every kana in a set is mastered, that is the
+// scheduler considers it learned and it's typically answered quickly.
+func (p *progression) mastered(values map[string]string, scheduler Scheduler) bool {
+	for kana := range values {
+		if !scheduler.Mastered(kana) {
+			return false
+		}
+		times := p.profile.KanaTimes[kana]
+		if len(times) == 0 || percentileOfDuration(times, 50.0) > p.masteryLatency {
+			return false
+		}
+	}
+	return true
+}
+
+// advance adds the next row with any kana for the included scripts to `values`
+// if every kana already in `values` is mastered, returning the row unlocked.
+func (p *progression) advance(values map[string]string, scheduler Scheduler) (unlocked row, ok bool) {
+	if !p.mastered(values, scheduler) {
+		return
+	}
+	for p.profile.Unlocked < len(rows) {
+		unlocked = rows[p.profile.Unlocked]
+		p.profile.Unlocked++
+		added := rowSet([]string{unlocked.Name}, p.includeHiragana, p.includeKatakana)
+		if len(added) == 0 {
+			continue
+		}
+		for kana, roman := range added {
+			values[kana] = roman
+		}
+		p.profile.init(values)
+		return unlocked, true
+	}
+	return row{}, false
+}
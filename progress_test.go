@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func Test_progression(t *testing.T) {
+	assert := assert.New(t)
+
+	p := newProfile("test")
+	progress := &progression{profile: p, includeHiragana: true, masteryLatency: time.Second}
+
+	values := progress.pool()
+	assert.Equal(map[string]string{"あ": "a", "い": "i", "う": "u", "え": "e", "お": "o"}, values)
+	p.init(values)
+
+	scheduler, err := newScheduler(schedulerWeighted, values, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.Nil(err)
+
+	_, ok := progress.advance(values, scheduler)
+	assert.False(ok)
+
+	for kana := range values {
+		for x := 0; x < 4; x++ {
+			scheduler.Record(kana, true, 500*time.Millisecond)
+			p.KanaTimes[kana] = append(p.KanaTimes[kana], 500*time.Millisecond)
+		}
+	}
+	unlocked, ok := progress.advance(values, scheduler)
+	assert.True(ok)
+	assert.Equal("ka", unlocked.Name)
+	assert.Equal(2, p.Unlocked)
+	assert.Len(values, 10)
+	assert.Equal(weightDefault, p.Weights["か"])
+
+	_, ok = progress.advance(values, scheduler)
+	assert.False(ok, "the new row shouldn't be mastered yet")
+}
+
+func Test_progression_katakanaOnlyRows(t *testing.T) {
+	assert := assert.New(t)
+
+	p := newProfile("test")
+	p.Unlocked = len(rows) - len(rowGroups["extended"])
+	progress := &progression{profile: p, includeHiragana: true, masteryLatency: time.Second}
+	values := progress.pool()
+	scheduler, err := newScheduler(schedulerWeighted, values, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.Nil(err)
+
+	for kana := range values {
+		p.Weights[kana] = weightMin
+		p.KanaTimes[kana] = []time.Duration{time.Millisecond}
+	}
+	_, ok := progress.advance(values, scheduler)
+	assert.False(ok, "there are no more rows for hiragana")
+	assert.Equal(len(rows), p.Unlocked)
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+const (
+	reportOutDefault  = "report.html"
+	reportDaysDefault = 30
+
+	reportCellSize      = 44
+	reportLabelWidth    = 40
+	reportChartWidth    = 640
+	reportChartHeight   = 200
+	reportChartPadding  = 40
+	reportBucketSize    = 250 * time.Millisecond
+	reportBuckets       = 20
+	reportLatencyFast   = 500 * time.Millisecond
+	reportLatencySlow   = 3500 * time.Millisecond
+	reportNoDataFill    = "#eeeeee"
+	reportHistogramFill = "#4a90d9"
+)
+
+// reportCell is a kana in a heat mapped grid.
+type reportCell struct {
+	X, Y  int
+	Kana  string
+	Label string
+	Title string
+	Fill  string
+}
+
+// reportGrid is a gojuon chart heat mapped by a stat.
+type reportGrid struct {
+	Title         string
+	Width, Height int
+	Rows          []reportAxisLabel
+	Cells         []reportCell
+}
+
+// reportAxisLabel is a label positioned along a chart axis.
+type reportAxisLabel struct {
+	X, Y  float64
+	Label string
+}
+
+// reportDot is a data point on a line chart.
+type reportDot struct {
+	X, Y  float64
+	Title string
+}
+
+// reportLineChart is a line chart of accuracy per day.
+type reportLineChart struct {
+	Width, Height int
+	Left, Right   float64
+	Points        string
+	Dots          []reportDot
+	XLabels       []reportAxisLabel
+	YLabels       []reportAxisLabel
+}
+
+// reportBar is a bar in a histogram.
+type reportBar struct {
+	X, Y, Width, Height float64
+	Title               string
+}
+
+// reportHistogram is a histogram of answer times.
+type reportHistogram struct {
+	Title         string
+	Width, Height int
+	Bars          []reportBar
+	XLabels       []reportAxisLabel
+}
+
+// report is everything rendered into the html report.
+type report struct {
+	Profile    string
+	Generated  string
+	Summary    stats
+	Grids      []reportGrid
+	Accuracy   reportLineChart
+	Histograms []reportHistogram
+}
+
+// runReport implements the `report` subcommand.
+func runReport(flags *flagSet, args []string) error {
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile to report on")
+	logPath := flags.String("log", "", "The event log to read (defaults to the profile's log)")
+	since := flags.String("since", "", "Only include answers since a date (e.g. 2019-10-01) or a duration ago (e.g. 72h or 30d)")
+	script := flags.String("script", "", "Only include a script (hiragana or katakana)")
+	days := flags.Int("days", reportDaysDefault, "The number of days to chart accuracy for")
+	out := flags.StringP("out", "o", reportOutDefault, "The file to write the report to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	events, err := loadEvents(*profileName, *logPath, *since, *script, now)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err = writeReport(f, buildReport(*profileName, events, now, *days)); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", *out)
+	return nil
+}
+
+// buildReport lays out the charts for a report from a list of events.
+func buildReport(profileName string, events []event, now time.Time, days int) report {
+	summary := computeStats(events, now, days, statsWeakestDefault)
+	byKana := make(map[string]kanaStats)
+	for _, ks := range summary.Kana {
+		byKana[ks.Kana] = ks
+	}
+
+	output := report{
+		Profile:   profileName,
+		Generated: now.Format(time.RFC1123),
+		Summary:   summary,
+		Accuracy:  accuracyChart(summary.Days),
+	}
+	for _, script := range []romaji.Script{romaji.ScriptHiragana, romaji.ScriptKatakana} {
+		name := scriptNames[script]
+		if !hasScript(byKana, name) {
+			continue
+		}
+		output.Grids = append(output.Grids,
+			gojuonGrid(name+" accuracy", byKana, script, func(ks kanaStats) (string, string) {
+				return formatAccuracy(ks.Accuracy), heatFill(1 - ks.Accuracy)
+			}),
+			gojuonGrid(name+" latency (p50)", byKana, script, func(ks kanaStats) (string, string) {
+				return formatLatency(ks.P50), heatFill(float64(ks.P50-reportLatencyFast) / float64(reportLatencySlow-reportLatencyFast))
+			}),
+		)
+	}
+
+	byScript := make(map[string][]time.Duration)
+	var all []time.Duration
+	for _, e := range events {
+		byScript[e.Script] = append(byScript[e.Script], e.Latency)
+		all = append(all, e.Latency)
+	}
+	output.Histograms = append(output.Histograms, latencyHistogram("all answers", all))
+	for _, script := range []string{"hiragana", "katakana", "mixed"} {
+		if len(byScript[script]) > 0 && len(byScript[script]) < len(all) {
+			output.Histograms = append(output.Histograms, latencyHistogram(script+" answers", byScript[script]))
+		}
+	}
+	return output
+}
+
+// scriptNames are the names used in the event log for each script.
+var scriptNames = map[romaji.Script]string{
+	romaji.ScriptHiragana: "hiragana",
+	romaji.ScriptKatakana: "katakana",
+}
+
+// hasScript returns if there are stats for any kana in a script.
+func hasScript(byKana map[string]kanaStats, name string) bool {
+	for kana := range byKana {
+		if scriptName(kana) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// gojuonGrid lays out the chart for a script, with each kana labelled and
+// colored by a given function.
+//
+// The katakana only rows are only shown if there are stats for them.
+func gojuonGrid(title string, byKana map[string]kanaStats, script romaji.Script, cell func(kanaStats) (label, fill string)) reportGrid {
+	output := reportGrid{Title: title, Width: reportLabelWidth + 5*reportCellSize}
+	for _, r := range rows {
+		if r.KatakanaOnly && (script == romaji.ScriptHiragana || !rowHasStats(r, byKana)) {
+			continue
+		}
+		y := len(output.Rows) * reportCellSize
+		output.Rows = append(output.Rows, reportAxisLabel{X: 0, Y: float64(y + reportCellSize/2), Label: r.Name})
+		for column, kana := range r.Kana {
+			if kana == "" {
+				continue
+			}
+			if script == romaji.ScriptKatakana {
+				kana = romaji.ToKatakana(kana)
+			}
+			c := reportCell{
+				X:     reportLabelWidth + column*reportCellSize,
+				Y:     y,
+				Kana:  kana,
+				Title: kana + ": no answers",
+				Fill:  reportNoDataFill,
+			}
+			if ks, ok := byKana[kana]; ok {
+				c.Label, c.Fill = cell(ks)
+				c.Title = fmt.Sprintf("%s: %d answered, %s correct, p50 %v, p95 %v", kana, ks.Total, formatAccuracy(ks.Accuracy), formatLatency(ks.P50), formatLatency(ks.P95))
+			}
+			output.Cells = append(output.Cells, c)
+		}
+	}
+	output.Height = len(output.Rows) * reportCellSize
+	return output
+}
+
+// rowHasStats returns if there are stats for any katakana in a row.
+func rowHasStats(r row, byKana map[string]kanaStats) bool {
+	for _, kana := range r.Kana {
+		if _, ok := byKana[romaji.ToKatakana(kana)]; ok && kana != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// accuracyChart lays out a line chart of accuracy per day, skipping days
+// without any answers.
+func accuracyChart(days []dayStats) reportLineChart {
+	output := reportLineChart{
+		Width:  reportChartWidth,
+		Height: reportChartHeight,
+		Left:   reportChartPadding,
+		Right:  reportChartWidth - reportChartPadding,
+	}
+	plotHeight := float64(reportChartHeight - 2*reportChartPadding)
+	step := output.Right - output.Left
+	if len(days) > 1 {
+		step = step / float64(len(days)-1)
+	}
+	labelEvery := len(days) / 6
+	if labelEvery < 1 {
+		labelEvery = 1
+	}
+
+	var points []string
+	for index, ds := range days {
+		x := output.Left + float64(index)*step
+		if index%labelEvery == 0 || index == len(days)-1 {
+			output.XLabels = append(output.XLabels, reportAxisLabel{X: x, Y: reportChartHeight - reportChartPadding/2, Label: ds.Day[5:]})
+		}
+		if ds.Total == 0 {
+			continue
+		}
+		y := reportChartPadding + (1-ds.Accuracy)*plotHeight
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		output.Dots = append(output.Dots, reportDot{X: x, Y: y, Title: fmt.Sprintf("%s: %s of %d", ds.Day, formatAccuracy(ds.Accuracy), ds.Total)})
+	}
+	output.Points = strings.Join(points, " ")
+	for _, percent := range []int{0, 50, 100} {
+		output.YLabels = append(output.YLabels, reportAxisLabel{
+			X:     output.Left,
+			Y:     reportChartPadding + (1-float64(percent)/100)*plotHeight,
+			Label: fmt.Sprintf("%d%%", percent),
+		})
+	}
+	return output
+}
+
+// latencyHistogram lays out a histogram of answer times in fixed size
+// buckets, with anything slower than the last bucket counted in it.
+func latencyHistogram(title string, times []time.Duration) reportHistogram {
+	counts := make([]int, reportBuckets)
+	var most int
+	for _, elapsed := range times {
+		bucket := min(int(elapsed/reportBucketSize), reportBuckets-1)
+		if bucket < 0 {
+			bucket = 0
+		}
+		counts[bucket]++
+		if counts[bucket] > most {
+			most = counts[bucket]
+		}
+	}
+
+	output := reportHistogram{Title: title, Width: reportChartWidth, Height: reportChartHeight}
+	plotHeight := float64(reportChartHeight - 2*reportChartPadding)
+	barWidth := float64(reportChartWidth-2*reportChartPadding) / reportBuckets
+	for bucket, count := range counts {
+		x := reportChartPadding + float64(bucket)*barWidth
+		height := 0.0
+		if most > 0 {
+			height = float64(count) / float64(most) * plotHeight
+		}
+		low, high := time.Duration(bucket)*reportBucketSize, time.Duration(bucket+1)*reportBucketSize
+		rangeLabel := fmt.Sprintf("%v-%v", low, high)
+		if bucket == reportBuckets-1 {
+			rangeLabel = fmt.Sprintf("%v+", low)
+		}
+		output.Bars = append(output.Bars, reportBar{
+			X:      x + 1,
+			Y:      reportChartPadding + plotHeight - height,
+			Width:  barWidth - 2,
+			Height: height,
+			Title:  fmt.Sprintf("%s: %d", rangeLabel, count),
+		})
+		if bucket%4 == 0 {
+			output.XLabels = append(output.XLabels, reportAxisLabel{X: x, Y: reportChartHeight - reportChartPadding/2, Label: fmt.Sprint(low)})
+		}
+	}
+	return output
+}
+
+// heatFill returns a color from green (0) to red (1).
+func heatFill(value float64) string {
+	if value < 0 {
+		value = 0
+	}
+	if value > 1 {
+		value = 1
+	}
+	return fmt.Sprintf("hsl(%.0f, 65%%, 55%%)", 120*(1-value))
+}
+
+// formatLatency formats an answer time for display.
+func formatLatency(value time.Duration) string {
+	return fmt.Sprint(value.Round(10 * time.Millisecond))
+}
+
+// writeReport renders a report as a self contained html page.
+func writeReport(wr io.Writer, r report) error {
+	return reportTemplate.Execute(wr, r)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"accuracy": formatAccuracy,
+	"duration": func(value time.Duration) time.Duration { return value.Round(time.Second) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>kana report: {{ .Profile }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h2 { margin-top: 1.5em; }
+.grids { display: flex; flex-wrap: wrap; gap: 2em; }
+svg text { font-size: 12px; }
+svg .kana { font-size: 16px; }
+</style>
+</head>
+<body>
+<h1>kana report: {{ .Profile }}</h1>
+<p>Generated {{ .Generated }}</p>
+<p>
+Total answered: {{ .Summary.Total }}, {{ accuracy .Summary.Accuracy }} correct<br>
+Time studied: {{ duration .Summary.Studied }} over {{ .Summary.Sessions }} sessions<br>
+Streak: {{ .Summary.CurrentStreak }} days (longest {{ .Summary.LongestStreak }} days)
+</p>
+
+<h2>Chart</h2>
+<div class="grids">
+{{- range .Grids }}
+<figure>
+<figcaption>{{ .Title }}</figcaption>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="{{ .Height }}">
+{{- range .Rows }}
+<text x="{{ .X }}" y="{{ .Y }}" dominant-baseline="middle">{{ .Label }}</text>
+{{- end }}
+{{- range .Cells }}
+<g><title>{{ .Title }}</title>
+<rect x="{{ .X }}" y="{{ .Y }}" width="42" height="42" fill="{{ .Fill }}"></rect>
+<text class="kana" x="{{ .X }}" y="{{ .Y }}" dx="21" dy="20" text-anchor="middle">{{ .Kana }}</text>
+<text x="{{ .X }}" y="{{ .Y }}" dx="21" dy="36" text-anchor="middle">{{ .Label }}</text>
+</g>
+{{- end }}
+</svg>
+</figure>
+{{- end }}
+</div>
+
+<h2>Accuracy per day</h2>
+{{- with .Accuracy }}
+<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="{{ .Height }}">
+{{- range .YLabels }}
+<line x1="{{ .X }}" x2="{{ $.Accuracy.Right }}" y1="{{ .Y }}" y2="{{ .Y }}" stroke="#dddddd"></line>
+<text x="{{ .X }}" y="{{ .Y }}" dx="-6" text-anchor="end" dominant-baseline="middle">{{ .Label }}</text>
+{{- end }}
+{{- range .XLabels }}
+<text x="{{ .X }}" y="{{ .Y }}" text-anchor="middle">{{ .Label }}</text>
+{{- end }}
+<polyline points="{{ .Points }}" fill="none" stroke="#4a90d9" stroke-width="2"></polyline>
+{{- range .Dots }}
+<circle cx="{{ .X }}" cy="{{ .Y }}" r="3" fill="#4a90d9"><title>{{ .Title }}</title></circle>
+{{- end }}
+</svg>
+{{- end }}
+
+<h2>Answer times</h2>
+{{- range .Histograms }}
+<figure>
+<figcaption>{{ .Title }}</figcaption>
+<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="{{ .Height }}">
+{{- range .Bars }}
+<rect x="{{ .X }}" y="{{ .Y }}" width="{{ .Width }}" height="{{ .Height }}" fill="` + reportHistogramFill + `"><title>{{ .Title }}</title></rect>
+{{- end }}
+{{- range .XLabels }}
+<text x="{{ .X }}" y="{{ .Y }}">{{ .Label }}</text>
+{{- end }}
+</svg>
+</figure>
+{{- end }}
+</body>
+</html>
+`))
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_buildReport(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2019, 10, 10, 12, 0, 0, 0, time.UTC)
+	events := []event{
+		{Timestamp: now.AddDate(0, 0, -1), Kana: "か", Script: "hiragana", Correct: true, Latency: time.Second},
+		{Timestamp: now, Kana: "か", Script: "hiragana", Correct: false, Latency: 2 * time.Second},
+		{Timestamp: now, Kana: "ヌ", Script: "katakana", Correct: true, Latency: 6 * time.Second},
+	}
+
+	r := buildReport("test", events, now, 7)
+	assert.Len(r.Grids, 4, "each script should have an accuracy and latency grid")
+	assert.Equal("hiragana accuracy", r.Grids[0].Title)
+	assert.Equal("katakana latency (p50)", r.Grids[3].Title)
+
+	var cell reportCell
+	for _, c := range r.Grids[0].Cells {
+		if c.Kana == "か" {
+			cell = c
+		}
+	}
+	assert.Equal("50.00%", cell.Label)
+	assert.Equal(heatFill(0.5), cell.Fill)
+
+	assert.Len(r.Accuracy.Dots, 2, "days without answers shouldn't be plotted")
+	assert.Len(r.Histograms, 3)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(writeReport(buffer, r))
+	html := buffer.String()
+	assert.Contains(html, "<svg")
+	assert.Contains(html, "か: 2 answered, 50.00% correct")
+	assert.NotContains(html, "<script")
+	assert.NotContains(html, "src=", "the report should be self contained")
+}
+
+func Test_gojuonGrid(t *testing.T) {
+	assert := assert.New(t)
+
+	cell := func(ks kanaStats) (string, string) { return "", "" }
+	byKana := map[string]kanaStats{"カ": {Kana: "カ"}}
+
+	hiragana := gojuonGrid("", byKana, romaji.ScriptHiragana, cell)
+	katakana := gojuonGrid("", byKana, romaji.ScriptKatakana, cell)
+	assert.Equal(len(hiragana.Rows), len(katakana.Rows), "extended rows should be hidden without stats")
+
+	byKana["ファ"] = kanaStats{Kana: "ファ"}
+	katakana = gojuonGrid("", byKana, romaji.ScriptKatakana, cell)
+	assert.Equal(len(hiragana.Rows)+1, len(katakana.Rows))
+	assert.True(strings.HasPrefix(katakana.Cells[0].Kana, "ア"))
+}
+
+func Test_latencyHistogram(t *testing.T) {
+	assert := assert.New(t)
+
+	histogram := latencyHistogram("", []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, time.Minute})
+	assert.Len(histogram.Bars, reportBuckets)
+	assert.Equal("0s-250ms: 2", histogram.Bars[0].Title)
+	assert.Equal("250ms-500ms: 1", histogram.Bars[1].Title)
+	assert.Equal("4.75s+: 1", histogram.Bars[reportBuckets-1].Title, "slow answers should land in the last bucket")
+	assert.True(histogram.Bars[0].Height > histogram.Bars[1].Height)
+}
+
+func Test_heatFill(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("hsl(120, 65%, 55%)", heatFill(-1))
+	assert.Equal("hsl(60, 65%, 55%)", heatFill(0.5))
+	assert.Equal("hsl(0, 65%, 55%)", heatFill(2))
+}
@@ -0,0 +1,395 @@
+/*
+Package romaji converts between kana and romaji.
+
+Kana are romanized from per-kana tables, handling yoon (e.g. きゃ),
+sokuon (っ), the long vowel mark (ー) and ambiguous ん, in any of
+the Hepburn, Kunrei-shiki and Nihon-shiki systems.
+*/
+package romaji
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Script is a kana script.
+type Script int
+
+// Scripts.
+const (
+	ScriptHiragana Script = iota
+	ScriptKatakana
+)
+
+// System is a romanization system.
+type System string
+
+// Systems.
+const (
+	Hepburn System = "hepburn"
+	Kunrei  System = "kunrei"
+	Nihon   System = "nihon"
+	// Any accepts the readings of every system, preferring hepburn.
+	Any System = "any"
+)
+
+const (
+	hiraganaStart  = 'ぁ'
+	hiraganaEnd    = 'ゖ'
+	katakanaStart  = 'ァ'
+	katakanaEnd    = 'ヶ'
+	katakanaOffset = katakanaStart - hiraganaStart
+)
+
+// kunreiReplacer converts hepburn readings to kunrei-shiki readings.
+//
+// Order matters here; the longer sequences have to be replaced first.
+var kunreiReplacer = strings.NewReplacer(
+	"shi", "si",
+	"sh", "sy",
+	"chi", "ti",
+	"ch", "ty",
+	"tsu", "tu",
+	"fu", "hu",
+	"ji", "zi",
+	"j", "zy",
+)
+
+// exceptions are readings that can't be derived from the hepburn
+// reading alone, keyed by hiragana and then by system.
+//
+// The extended ti, di, tu and du are spelled thi, dhi, twu and dwu in
+// kunrei-shiki and nihon-shiki, where ti, di, tu and du are ち, ぢ, つ and づ.
+var exceptions = map[string]map[System][]string{
+	"ぢ":  {Hepburn: {"ji"}, Kunrei: {"zi"}, Nihon: {"di"}},
+	"づ":  {Hepburn: {"zu"}, Kunrei: {"zu"}, Nihon: {"du"}},
+	"を":  {Hepburn: {"wo", "o"}, Kunrei: {"o"}, Nihon: {"wo"}},
+	"ん":  {Hepburn: {"n", "nn"}, Kunrei: {"n", "nn"}, Nihon: {"n", "nn"}},
+	"とぅ": {Hepburn: {"tu", "twu"}, Kunrei: {"twu"}, Nihon: {"twu"}},
+	"どぅ": {Hepburn: {"du", "dwu"}, Kunrei: {"dwu"}, Nihon: {"dwu"}},
+	"てぃ": {Hepburn: {"ti"}, Kunrei: {"thi"}, Nihon: {"thi"}},
+	"でぃ": {Hepburn: {"di"}, Kunrei: {"dhi"}, Nihon: {"dhi"}},
+}
+
+// macrons are the hepburn long vowel forms.
+var macrons = map[byte]string{
+	'a': "ā",
+	'i': "ī",
+	'u': "ū",
+	'e': "ē",
+	'o': "ō",
+}
+
+// tables are all the per-kana tables merged.
+var tables = merge(Hiragana, Katakana, HiraganaYoon, KatakanaYoon, KatakanaExtended)
+
+// hiraganaReadings and katakanaReadings map readings in every system
+// back to kana, for `ToKana`.
+var (
+	hiraganaReadings = reverse(merge(Hiragana, HiraganaYoon))
+	katakanaReadings = reverse(merge(Katakana, KatakanaYoon), KatakanaExtended)
+)
+
+// ParseSystem parses a romanization system.
+func ParseSystem(value string) (System, error) {
+	switch system := System(value); system {
+	case Hepburn, Kunrei, Nihon, Any:
+		return system, nil
+	default:
+		return "", fmt.Errorf("invalid romanization: %q (expected one of %s, %s, %s, %s)", value, Hepburn, Kunrei, Nihon, Any)
+	}
+}
+
+// ToRomaji returns the hepburn romanization of a kana string.
+func ToRomaji(value string) (string, error) {
+	romanizations, err := Romanize(value, Hepburn)
+	if err != nil {
+		return "", err
+	}
+	return romanizations[0], nil
+}
+
+// Romanize returns every accepted romanization of a kana string in
+// a given system. The first romanization is the canonical one.
+//
+// Sokuon (っ) doubles the following consonant, the long vowel mark (ー)
+// may be written as a doubled vowel or with a macron, and ん before
+// a vowel or y is written as n' or nn.
+func Romanize(value string, system System) ([]string, error) {
+	runes := []rune(value)
+	var segments [][]string
+	var sokuon bool
+	for index := 0; index < len(runes); {
+		switch runes[index] {
+		case 'っ', 'ッ':
+			sokuon = true
+			index++
+			continue
+		case 'ー':
+			if len(segments) == 0 {
+				return nil, fmt.Errorf("%s: long vowel mark without a preceding kana", value)
+			}
+			segments[len(segments)-1] = lengthen(segments[len(segments)-1])
+			index++
+			continue
+		}
+
+		token, hepburn, ok := match(runes[index:])
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown kana %q", value, string(runes[index]))
+		}
+		index += len([]rune(token))
+
+		alternatives := readings(token, hepburn, system)
+		if hepburn == "n" {
+			if _, next, ok := match(runes[index:]); ok && strings.ContainsAny(next[:1], "aiueoy") {
+				alternatives = []string{"n'", "nn"}
+			}
+		}
+		if sokuon {
+			alternatives = geminate(alternatives)
+			sokuon = false
+		}
+		segments = append(segments, alternatives)
+	}
+	if sokuon {
+		return nil, fmt.Errorf("%s: sokuon without a following kana", value)
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty kana string")
+	}
+	return combine(segments), nil
+}
+
+// ToKana converts romaji in any system to kana in a given script.
+//
+// A doubled consonant becomes sokuon (っ), and n becomes ん unless it
+// is followed by a vowel or y; use n' or nn to force ん (e.g. kin'en).
+// Macrons are written as a doubled vowel in hiragana and with the long
+// vowel mark in katakana, as is a hyphen.
+func ToKana(value string, script Script) (string, error) {
+	kana := hiraganaReadings
+	if script == ScriptKatakana {
+		kana = katakanaReadings
+	}
+
+	input := strings.ToLower(value)
+	for vowel, macron := range macrons {
+		switch {
+		case script == ScriptKatakana:
+			input = strings.Replace(input, macron, string(vowel)+"-", -1)
+		case vowel == 'o':
+			input = strings.Replace(input, macron, "ou", -1)
+		default:
+			input = strings.Replace(input, macron, string(vowel)+string(vowel), -1)
+		}
+	}
+
+	var output strings.Builder
+	for index := 0; index < len(input); {
+		c := input[index]
+		switch {
+		case c == '-':
+			output.WriteRune('ー')
+			index++
+			continue
+		case c == 'n' && !isSyllableStart(input, index+1):
+			output.WriteRune('ん')
+			index++
+			if index < len(input) && (input[index] == '\'' || (input[index] == 'n' && !isSyllableStart(input, index+1))) {
+				index++
+			}
+			continue
+		case c != 'n' && isConsonant(c) && index+1 < len(input) && (input[index+1] == c || (c == 't' && strings.HasPrefix(input[index+1:], "ch"))):
+			output.WriteRune('っ')
+			index++
+			continue
+		}
+
+		var matched bool
+		for length := 3; length > 0; length-- {
+			if index+length > len(input) {
+				continue
+			}
+			if match, ok := kana[input[index:index+length]]; ok {
+				output.WriteString(match)
+				index += length
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", fmt.Errorf("%s: invalid romaji at %q", value, input[index:])
+		}
+	}
+	if script == ScriptKatakana {
+		return ToKatakana(output.String()), nil
+	}
+	return output.String(), nil
+}
+
+// ToKatakana converts any hiragana in a string to katakana.
+func ToKatakana(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= hiraganaStart && r <= hiraganaEnd {
+			return r + katakanaOffset
+		}
+		return r
+	}, value)
+}
+
+// ToHiragana converts any katakana in a string to hiragana.
+func ToHiragana(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= katakanaStart && r <= katakanaEnd {
+			return r - katakanaOffset
+		}
+		return r
+	}, value)
+}
+
+// readings returns the accepted readings of a single kana in a given system.
+func readings(token, hepburn string, system System) []string {
+	if system == Any {
+		var output []string
+		for _, each := range []System{Hepburn, Kunrei, Nihon} {
+			output = appendUnique(output, readings(token, hepburn, each)...)
+		}
+		return output
+	}
+	if byKana, ok := exceptions[ToHiragana(token)]; ok {
+		if output, ok := byKana[system]; ok {
+			return output
+		}
+	}
+	switch system {
+	case Kunrei, Nihon:
+		return []string{kunreiReplacer.Replace(hepburn)}
+	default:
+		return []string{hepburn}
+	}
+}
+
+// match returns the longest kana at the start of a slice of runes.
+func match(runes []rune) (token, hepburn string, ok bool) {
+	for length := 2; length > 0; length-- {
+		if len(runes) < length {
+			continue
+		}
+		token = string(runes[:length])
+		if hepburn, ok = tables[token]; ok {
+			return
+		}
+	}
+	return "", "", false
+}
+
+// geminate doubles the leading consonant of each reading, e.g. for っか.
+func geminate(alternatives []string) []string {
+	var output []string
+	for _, alternative := range alternatives {
+		if strings.HasPrefix(alternative, "ch") {
+			output = appendUnique(output, "t"+alternative, "c"+alternative)
+			continue
+		}
+		output = appendUnique(output, alternative[:1]+alternative)
+	}
+	return output
+}
+
+// lengthen adds the long vowel forms of each reading, e.g. for コー.
+func lengthen(alternatives []string) []string {
+	var output []string
+	for _, alternative := range alternatives {
+		vowel := alternative[len(alternative)-1]
+		if macron, ok := macrons[vowel]; ok {
+			output = appendUnique(output, alternative+string(vowel), alternative[:len(alternative)-1]+macron)
+			continue
+		}
+		output = appendUnique(output, alternative)
+	}
+	return output
+}
+
+// combine returns every combination of a list of alternatives,
+// starting with the combination of the first alternative of each.
+func combine(segments [][]string) []string {
+	output := []string{""}
+	for _, alternatives := range segments {
+		var next []string
+		for _, prefix := range output {
+			for _, alternative := range alternatives {
+				next = append(next, prefix+alternative)
+			}
+		}
+		output = next
+	}
+	return output
+}
+
+// reverse builds a table from readings in every system back to kana.
+//
+// Where kana share a reading, hepburn readings win over the other
+// systems, earlier sets win over later sets, and otherwise kana are
+// visited in code point order, e.g. "ji" is じ rather than ぢ, and
+// "wo" is ヲ rather than ウォ.
+func reverse(sets ...map[string]string) map[string]string {
+	output := make(map[string]string)
+	for _, system := range []System{Hepburn, Kunrei, Nihon} {
+		for _, set := range sets {
+			keys := make([]string, 0, len(set))
+			for key := range set {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				for _, reading := range readings(key, set[key], system) {
+					if _, ok := output[reading]; !ok {
+						output[reading] = key
+					}
+				}
+			}
+		}
+	}
+	return output
+}
+
+// merge merges a number of tables.
+func merge(sets ...map[string]string) map[string]string {
+	output := make(map[string]string)
+	for _, set := range sets {
+		for key, value := range set {
+			output[key] = value
+		}
+	}
+	return output
+}
+
+// isConsonant returns if a letter is a consonant.
+func isConsonant(c byte) bool {
+	return c >= 'a' && c <= 'z' && !strings.ContainsRune("aiueo", rune(c))
+}
+
+// isSyllableStart returns if the letter at an index continues a syllable
+// started with n, i.e. it is a vowel or y.
+func isSyllableStart(input string, index int) bool {
+	return index < len(input) && strings.ContainsRune("aiueoy", rune(input[index]))
+}
+
+// appendUnique appends values to a list if they aren't already present.
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		var found bool
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
@@ -0,0 +1,148 @@
+package romaji
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func TestTables(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Len(KatakanaYoon, 33)
+	assert.Len(HiraganaYoon, 33)
+	assert.Len(Katakana, len(Hiragana))
+	for kana, roman := range Hiragana {
+		assert.Equal(roman, Katakana[ToKatakana(kana)], kana)
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	assert := assert.New(t)
+
+	system, err := ParseSystem("kunrei")
+	assert.Nil(err)
+	assert.Equal(Kunrei, system)
+
+	_, err = ParseSystem("wapuro")
+	assert.NotNil(err)
+}
+
+func TestRomanize(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		Input    string
+		System   System
+		Expected []string
+	}{
+		{"し", Hepburn, []string{"shi"}},
+		{"し", Kunrei, []string{"si"}},
+		{"しょ", Nihon, []string{"syo"}},
+		{"ちゅ", Kunrei, []string{"tyu"}},
+		{"ツ", Kunrei, []string{"tu"}},
+		{"ふ", Kunrei, []string{"hu"}},
+		{"じゃ", Kunrei, []string{"zya"}},
+		{"ヂ", Kunrei, []string{"zi"}},
+		{"ヂ", Nihon, []string{"di"}},
+		{"を", Kunrei, []string{"o"}},
+		{"ん", Hepburn, []string{"n", "nn"}},
+		{"し", Any, []string{"shi", "si"}},
+		{"ぢ", Any, []string{"ji", "zi", "di"}},
+		{"きんえん", Hepburn, []string{"kin'en", "kin'enn", "kinnen", "kinnenn"}},
+		{"コーヒー", Hepburn, []string{"koohii", "koohī", "kōhii", "kōhī"}},
+		{"まっちゃ", Hepburn, []string{"matcha", "maccha"}},
+		{"まっちゃ", Kunrei, []string{"mattya"}},
+		{"トゥ", Hepburn, []string{"tu", "twu"}},
+		{"ティ", Kunrei, []string{"thi"}},
+		{"ディ", Nihon, []string{"dhi"}},
+		{"トゥ", Nihon, []string{"twu"}},
+		{"チ", Kunrei, []string{"ti"}},
+		{"シェ", Kunrei, []string{"sye"}},
+		{"パーティー", Hepburn, []string{"paatii", "paatī", "pātii", "pātī"}},
+		{"ヴァイオリン", Hepburn, []string{"vaiorin", "vaiorinn"}},
+	}
+	for _, tc := range testCases {
+		actual, err := Romanize(tc.Input, tc.System)
+		assert.Nil(err)
+		assert.Equal(tc.Expected, actual, tc.Input)
+	}
+
+	for _, invalid := range []string{"", "ーあ", "あっ", "cat"} {
+		_, err := Romanize(invalid, Hepburn)
+		assert.NotNil(err, invalid)
+	}
+}
+
+func TestToRomaji(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		Input    string
+		Expected string
+	}{
+		{"ねこ", "neko"},
+		{"がっこう", "gakkou"},
+		{"ざっし", "zasshi"},
+		{"ほんや", "hon'ya"},
+		{"しんぶん", "shinbun"},
+		{"コンピューター", "konpyuutaa"},
+		{"ベッド", "beddo"},
+	}
+	for _, tc := range testCases {
+		actual, err := ToRomaji(tc.Input)
+		assert.Nil(err)
+		assert.Equal(tc.Expected, actual)
+	}
+}
+
+func TestToKana(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		Input    string
+		Script   Script
+		Expected string
+	}{
+		{"neko", ScriptHiragana, "ねこ"},
+		{"gakkou", ScriptHiragana, "がっこう"},
+		{"matcha", ScriptHiragana, "まっちゃ"},
+		{"si", ScriptHiragana, "し"},
+		{"ji", ScriptHiragana, "じ"},
+		{"di", ScriptHiragana, "ぢ"},
+		{"wo", ScriptHiragana, "を"},
+		{"o", ScriptHiragana, "お"},
+		{"kin'en", ScriptHiragana, "きんえん"},
+		{"kinnen", ScriptHiragana, "きんねん"},
+		{"hon'ya", ScriptHiragana, "ほんや"},
+		{"konnichiha", ScriptHiragana, "こんにちは"},
+		{"shinbun", ScriptHiragana, "しんぶん"},
+		{"hon", ScriptHiragana, "ほん"},
+		{"tōkyō", ScriptHiragana, "とうきょう"},
+		{"koohii", ScriptKatakana, "コオヒイ"},
+		{"kōhī", ScriptKatakana, "コーヒー"},
+		{"ko-hi-", ScriptKatakana, "コーヒー"},
+		{"Beddo", ScriptKatakana, "ベッド"},
+		{"wo", ScriptKatakana, "ヲ"},
+		{"fairu", ScriptKatakana, "ファイル"},
+		{"pa-ti-", ScriptKatakana, "パーティー"},
+		{"vaiorin", ScriptKatakana, "ヴァイオリン"},
+		{"chekku", ScriptKatakana, "チェック"},
+	}
+	for _, tc := range testCases {
+		actual, err := ToKana(tc.Input, tc.Script)
+		assert.Nil(err, tc.Input)
+		assert.Equal(tc.Expected, actual, tc.Input)
+	}
+
+	_, err := ToKana("xyz", ScriptHiragana)
+	assert.NotNil(err)
+}
+
+func TestToKatakana(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("シャ", ToKatakana("しゃ"))
+	assert.Equal("しゃ", ToHiragana("シャ"))
+	assert.Equal("コーヒー", ToKatakana("コーヒー"))
+}
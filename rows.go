@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// row is a row of the gojuon chart laid out in a-i-u-e-o columns.
+//
+// Rows are written in hiragana unless they are katakana only.
+type row struct {
+	Name         string
+	Kana         [5]string
+	KatakanaOnly bool
+}
+
+// rows are the rows of the chart in the order they're typically taught.
+var rows = []row{
+	{Name: "a", Kana: [5]string{"あ", "い", "う", "え", "お"}},
+	{Name: "ka", Kana: [5]string{"か", "き", "く", "け", "こ"}},
+	{Name: "sa", Kana: [5]string{"さ", "し", "す", "せ", "そ"}},
+	{Name: "ta", Kana: [5]string{"た", "ち", "つ", "て", "と"}},
+	{Name: "na", Kana: [5]string{"な", "に", "ぬ", "ね", "の"}},
+	{Name: "ha", Kana: [5]string{"は", "ひ", "ふ", "へ", "ほ"}},
+	{Name: "ma", Kana: [5]string{"ま", "み", "む", "め", "も"}},
+	{Name: "ya", Kana: [5]string{"や", "", "ゆ", "", "よ"}},
+	{Name: "ra", Kana: [5]string{"ら", "り", "る", "れ", "ろ"}},
+	{Name: "wa", Kana: [5]string{"わ", "", "", "", "を"}},
+	{Name: "n", Kana: [5]string{"ん", "", "", "", ""}},
+	{Name: "ga", Kana: [5]string{"が", "ぎ", "ぐ", "げ", "ご"}},
+	{Name: "za", Kana: [5]string{"ざ", "じ", "ず", "ぜ", "ぞ"}},
+	{Name: "da", Kana: [5]string{"だ", "ぢ", "づ", "で", "ど"}},
+	{Name: "ba", Kana: [5]string{"ば", "び", "ぶ", "べ", "ぼ"}},
+	{Name: "pa", Kana: [5]string{"ぱ", "ぴ", "ぷ", "ぺ", "ぽ"}},
+	{Name: "kya", Kana: [5]string{"きゃ", "", "きゅ", "", "きょ"}},
+	{Name: "sha", Kana: [5]string{"しゃ", "", "しゅ", "", "しょ"}},
+	{Name: "cha", Kana: [5]string{"ちゃ", "", "ちゅ", "", "ちょ"}},
+	{Name: "nya", Kana: [5]string{"にゃ", "", "にゅ", "", "にょ"}},
+	{Name: "hya", Kana: [5]string{"ひゃ", "", "ひゅ", "", "ひょ"}},
+	{Name: "mya", Kana: [5]string{"みゃ", "", "みゅ", "", "みょ"}},
+	{Name: "rya", Kana: [5]string{"りゃ", "", "りゅ", "", "りょ"}},
+	{Name: "gya", Kana: [5]string{"ぎゃ", "", "ぎゅ", "", "ぎょ"}},
+	{Name: "ja", Kana: [5]string{"じゃ", "", "じゅ", "", "じょ"}},
+	{Name: "bya", Kana: [5]string{"びゃ", "", "びゅ", "", "びょ"}},
+	{Name: "pya", Kana: [5]string{"ぴゃ", "", "ぴゅ", "", "ぴょ"}},
+	{Name: "va", Kana: [5]string{"ヴァ", "ヴィ", "ヴ", "ヴェ", "ヴォ"}, KatakanaOnly: true},
+	{Name: "fa", Kana: [5]string{"ファ", "フィ", "フュ", "フェ", "フォ"}, KatakanaOnly: true},
+	{Name: "wi", Kana: [5]string{"", "ウィ", "", "ウェ", "ウォ"}, KatakanaOnly: true},
+	{Name: "ti", Kana: [5]string{"", "ティ", "トゥ", "", ""}, KatakanaOnly: true},
+	{Name: "di", Kana: [5]string{"", "ディ", "ドゥ", "", ""}, KatakanaOnly: true},
+	{Name: "tyu", Kana: [5]string{"", "", "テュ", "", ""}, KatakanaOnly: true},
+	{Name: "dyu", Kana: [5]string{"", "", "デュ", "", ""}, KatakanaOnly: true},
+	{Name: "she", Kana: [5]string{"", "", "", "シェ", ""}, KatakanaOnly: true},
+	{Name: "je", Kana: [5]string{"", "", "", "ジェ", ""}, KatakanaOnly: true},
+	{Name: "che", Kana: [5]string{"", "", "", "チェ", ""}, KatakanaOnly: true},
+	{Name: "tsa", Kana: [5]string{"ツァ", "ツィ", "", "ツェ", "ツォ"}, KatakanaOnly: true},
+	{Name: "ye", Kana: [5]string{"", "", "", "イェ", ""}, KatakanaOnly: true},
+	{Name: "kwa", Kana: [5]string{"クァ", "", "", "", ""}, KatakanaOnly: true},
+	{Name: "gwa", Kana: [5]string{"グァ", "", "", "", ""}, KatakanaOnly: true},
+}
+
+// rowGroups are names for common sets of rows.
+var rowGroups = map[string][]string{
+	"vowels":     {"a"},
+	"gojuon":     {"a", "ka", "sa", "ta", "na", "ha", "ma", "ya", "ra", "wa", "n"},
+	"dakuten":    {"ga", "za", "da", "ba"},
+	"handakuten": {"pa"},
+	"yoon":       {"kya", "sha", "cha", "nya", "hya", "mya", "rya", "gya", "ja", "bya", "pya"},
+	"extended":   {"va", "fa", "wi", "ti", "di", "tyu", "dyu", "she", "je", "che", "tsa", "ye", "kwa", "gwa"},
+}
+
+// kanaOrder is the position of each kana (in either script) in the chart.
+var kanaOrder = orderKana(rows)
+
+// orderKana numbers each kana by row, then by script, then by column.
+func orderKana(chart []row) map[string]int {
+	output := make(map[string]int)
+	for rowIndex, r := range chart {
+		for column, kana := range r.Kana {
+			if kana == "" {
+				continue
+			}
+			if !r.KatakanaOnly {
+				output[kana] = rowIndex*10 + column
+			}
+			output[romaji.ToKatakana(kana)] = rowIndex*10 + 5 + column
+		}
+	}
+	return output
+}
+
+// findRow returns a row by name.
+func findRow(name string) (row, bool) {
+	for _, r := range rows {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return row{}, false
+}
+
+// parseRows parses a comma separated list of row and group names.
+func parseRows(value string) ([]string, error) {
+	var output []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if group, ok := rowGroups[name]; ok {
+			output = append(output, group...)
+			continue
+		}
+		if _, ok := findRow(name); !ok {
+			return nil, fmt.Errorf("invalid row: %q (expected a row like a, ka, kya, or a group like vowels, dakuten, yoon)", name)
+		}
+		output = append(output, name)
+	}
+	return output, nil
+}
+
+// lessonRows returns the rows unlocked by a given lesson, i.e. the first `lesson` rows.
+func lessonRows(lesson int) ([]string, error) {
+	if lesson < 1 || lesson > len(rows) {
+		return nil, fmt.Errorf("invalid lesson: %d (expected between 1 and %d)", lesson, len(rows))
+	}
+	var output []string
+	for _, r := range rows[:lesson] {
+		output = append(output, r.Name)
+	}
+	return output, nil
+}
+
+// validateSelection returns an error if more than one way of picking the kana
+// to quiz is given; rows and a lesson can be combined, but progressive mode
+// and words pick their own kana.
+func validateSelection(progressive, words bool, rowNames string, lesson int) error {
+	rows := rowNames != "" || lesson > 0
+	switch {
+	case progressive && (words || rows):
+		return fmt.Errorf("invalid selection: --progressive can't be combined with --words, --rows or --lesson")
+	case words && rows:
+		return fmt.Errorf("invalid selection: --words can't be combined with --rows or --lesson")
+	default:
+		return nil
+	}
+}
+
+// rowSet returns the kana in the given rows, and their readings, for the included scripts.
+func rowSet(names []string, includeHiragana, includeKatakana bool) map[string]string {
+	tables := mergeSets(romaji.Hiragana, romaji.HiraganaYoon, romaji.Katakana, romaji.KatakanaYoon, romaji.KatakanaExtended)
+	output := make(map[string]string)
+	for _, name := range names {
+		r, _ := findRow(name)
+		for _, kana := range r.Kana {
+			if kana == "" {
+				continue
+			}
+			if includeHiragana && !r.KatakanaOnly {
+				output[kana] = tables[kana]
+			}
+			if includeKatakana {
+				output[romaji.ToKatakana(kana)] = tables[romaji.ToKatakana(kana)]
+			}
+		}
+	}
+	return output
+}
+
+// sortKana sorts kana in chart order, with anything not in the chart last.
+func sortKana(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		return kanaLess(keys[i], keys[j])
+	})
+}
+
+// kanaLess returns if a kana comes before another in chart order.
+func kanaLess(a, b string) bool {
+	av, aok := kanaOrder[a]
+	bv, bok := kanaOrder[b]
+	switch {
+	case aok && bok:
+		return av < bv
+	case aok != bok:
+		return aok
+	default:
+		return a < b
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_rows(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := mergeSets(romaji.Hiragana, romaji.HiraganaYoon, romaji.Katakana, romaji.KatakanaYoon, romaji.KatakanaExtended)
+	var count int
+	for _, r := range rows {
+		for _, kana := range r.Kana {
+			if kana == "" {
+				continue
+			}
+			count++
+			assert.NotEmpty(tables[romaji.ToKatakana(kana)], kana)
+			if !r.KatakanaOnly {
+				count++
+				assert.NotEmpty(tables[kana], kana)
+			}
+		}
+	}
+	assert.Equal(len(tables), count, "every kana should be in exactly one row")
+}
+
+func Test_parseRows(t *testing.T) {
+	assert := assert.New(t)
+
+	names, err := parseRows("a, KA,dakuten")
+	assert.Nil(err)
+	assert.Equal([]string{"a", "ka", "ga", "za", "da", "ba"}, names)
+
+	_, err = parseRows("a,xa")
+	assert.NotNil(err)
+}
+
+func Test_lessonRows(t *testing.T) {
+	assert := assert.New(t)
+
+	names, err := lessonRows(3)
+	assert.Nil(err)
+	assert.Equal([]string{"a", "ka", "sa"}, names)
+
+	_, err = lessonRows(0)
+	assert.NotNil(err)
+}
+
+func Test_validateSelection(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(validateSelection(false, false, "", 0))
+	assert.Nil(validateSelection(true, false, "", 0))
+	assert.Nil(validateSelection(false, true, "", 0))
+	assert.Nil(validateSelection(false, false, "a,ka", 2), "rows and a lesson can be combined")
+	assert.NotNil(validateSelection(true, true, "", 0))
+	assert.NotNil(validateSelection(true, false, "a", 0))
+	assert.NotNil(validateSelection(true, false, "", 2))
+	assert.NotNil(validateSelection(false, true, "a", 0))
+	assert.NotNil(validateSelection(false, true, "", 2))
+}
+
+func Test_rowSet(t *testing.T) {
+	assert := assert.New(t)
+
+	values := rowSet([]string{"ya"}, true, true)
+	assert.Equal(map[string]string{"や": "ya", "ゆ": "yu", "よ": "yo", "ヤ": "ya", "ユ": "yu", "ヨ": "yo"}, values)
+
+	values = rowSet([]string{"fa", "a"}, true, false)
+	assert.Len(values, 5)
+}
+
+func Test_selectCount_order(t *testing.T) {
+	assert := assert.New(t)
+
+	values := selectCount(mergeSets(romaji.Hiragana, romaji.Katakana), 7)
+	assert.Equal(map[string]string{"あ": "a", "い": "i", "う": "u", "え": "e", "お": "o", "ア": "a", "イ": "i"}, values)
+}
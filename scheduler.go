@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Scheduler names.
+const (
+	schedulerWeighted = "weighted"
+	schedulerSM2      = "sm2"
+	schedulerLeitner  = "leitner"
+)
+
+const (
+	weightSlowFactor   = 2.0
+	slowRatioDefault   = 2.0
+	slowSamplesDefault = 20
+
+	sm2EaseDefault    = 2.5
+	sm2EaseMin        = 1.3
+	sm2RelearnDelay   = time.Minute
+	sm2FastAnswer     = 2 * time.Second
+	sm2SlowAnswer     = 5 * time.Second
+	leitnerBoxes      = 5
+	leitnerBoxDefault = 1
+)
+
+// leitnerIntervals are how long a card waits in each box before it is due again.
+var leitnerIntervals = [leitnerBoxes + 1]time.Duration{
+	1: 0,
+	2: 24 * time.Hour,
+	3: 3 * 24 * time.Hour,
+	4: 7 * 24 * time.Hour,
+	5: 14 * 24 * time.Hour,
+}
+
+// Scheduler decides which kana to ask next and learns from the answers.
+type Scheduler interface {
+	// Next returns the next kana to ask, avoiding any in `exclude` if it can.
+	Next(exclude []string) string
+	// Record records the result of asking a kana.
+	Record(kana string, correct bool, elapsed time.Duration)
+	// Weight returns how much a kana still needs practice; higher is weaker.
+	Weight(kana string) float64
+	// Mastered returns if a kana is considered learned.
+	Mastered(kana string) bool
+}
+
+// latencyPolicy decides when a correct answer was too slow to count as fluent.
+//
+// Answers are compared to the learner's own median answer time, so
+// what counts as slow adapts as they get faster.
+type latencyPolicy struct {
+	// SlowRatio is how many times slower than the median an answer has to be to be slow; 0 disables.
+	SlowRatio float64
+	// MinSamples is how many answer times are needed before answers can be slow.
+	MinSamples int
+	// Times returns the learner's answer times.
+	Times func() []time.Duration
+}
+
+// median returns the learner's median answer time, or false if there aren't enough samples.
+func (lp latencyPolicy) median() (time.Duration, bool) {
+	if lp.SlowRatio <= 0 || lp.Times == nil {
+		return 0, false
+	}
+	times := lp.Times()
+	if len(times) < lp.MinSamples || len(times) == 0 {
+		return 0, false
+	}
+	return percentileOfDuration(times, 50.0), true
+}
+
+// IsSlow returns if a correct answer was slow.
+func (lp latencyPolicy) IsSlow(elapsed time.Duration) bool {
+	median, ok := lp.median()
+	return ok && float64(elapsed) > float64(median)*lp.SlowRatio
+}
+
+// IsFast returns if a correct answer was at least as fast as the median, or
+// under a fixed threshold if there aren't enough samples yet.
+func (lp latencyPolicy) IsFast(elapsed, fallback time.Duration) bool {
+	if median, ok := lp.median(); ok {
+		return elapsed <= median
+	}
+	return elapsed < fallback
+}
+
+// card is the persisted spaced repetition state for a kana.
+type card struct {
+	Ease        float64       `json:"ease,omitempty"`
+	Interval    time.Duration `json:"interval,omitempty"`
+	Repetitions int           `json:"repetitions,omitempty"`
+	Box         int           `json:"box,omitempty"`
+	Due         time.Time     `json:"due,omitempty"`
+}
+
+// newScheduler returns a scheduler by name backed by a given profile.
+//
+// The spaced repetition schedulers use `now` to decide which kana are due,
+// which should be the session's clock.
+func newScheduler(name string, values map[string]string, p *profile, latency latencyPolicy, random *rand.Rand, now func() time.Time) (Scheduler, error) {
+	switch name {
+	case schedulerWeighted:
+		return &weightedScheduler{values: values, weights: p.Weights, latency: latency, random: random}, nil
+	case schedulerSM2:
+		return &sm2Scheduler{values: values, cards: p.Cards, latency: latency, now: now, random: random}, nil
+	case schedulerLeitner:
+		return &leitnerScheduler{values: values, cards: p.Cards, latency: latency, now: now, random: random}, nil
+	default:
+		return nil, fmt.Errorf("invalid scheduler: %q (expected one of %s, %s, %s)", name, schedulerWeighted, schedulerSM2, schedulerLeitner)
+	}
+}
+
+// weightedScheduler selects kana randomly in proportion to their weights,
+// increasing the weight of kana answered incorrectly and decreasing
+// the weight of kana answered correctly.
+//
+// Correct answers that were slow count as a partial failure and
+// increase the weight by a smaller factor.
+type weightedScheduler struct {
+	values  map[string]string
+	weights map[string]float64
+	latency latencyPolicy
+	random  *rand.Rand
+}
+
+// Next implements Scheduler.
+func (ws *weightedScheduler) Next(exclude []string) string {
+	if len(exclude) >= len(ws.values) {
+		exclude = nil
+	}
+	for {
+		kana, _ := selectWeighted(ws.random, ws.values, ws.weights)
+		if !listHas(exclude, kana) {
+			return kana
+		}
+	}
+}
+
+// Record implements Scheduler.
+func (ws *weightedScheduler) Record(kana string, correct bool, elapsed time.Duration) {
+	switch {
+	case !correct:
+		increaseWeight(ws.weights, kana)
+	case ws.latency.IsSlow(elapsed):
+		if weight, ok := ws.weights[kana]; ok && weight < weightMax {
+			ws.weights[kana] = math.Min(weight*weightSlowFactor, weightMax)
+		}
+	default:
+		decreaseWeight(ws.weights, kana)
+	}
+}
+
+// Weight implements Scheduler.
+func (ws *weightedScheduler) Weight(kana string) float64 {
+	return ws.weights[kana]
+}
+
+// Mastered implements Scheduler.
+func (ws *weightedScheduler) Mastered(kana string) bool {
+	weight, ok := ws.weights[kana]
+	return ok && weight <= weightMin
+}
+
+// sm2Scheduler implements the SuperMemo 2 algorithm.
+//
+// Answer quality is derived from correctness and latency; kana answered
+// incorrectly are relearned after a short delay rather than the next day.
+type sm2Scheduler struct {
+	values  map[string]string
+	cards   map[string]*card
+	latency latencyPolicy
+	now     func() time.Time
+	random  *rand.Rand
+}
+
+// Next implements Scheduler.
+func (ss *sm2Scheduler) Next(exclude []string) string {
+	return selectEarliestDue(ss.random, ss.values, exclude, ss.card)
+}
+
+// Record implements Scheduler.
+//
+// A correct answer to a card that isn't due yet (e.g. because nothing else
+// was due) leaves its schedule alone, so one sitting can't push a card out
+// by weeks; an incorrect answer always sends it back to be relearned.
+func (ss *sm2Scheduler) Record(kana string, correct bool, elapsed time.Duration) {
+	c := ss.card(kana)
+	now := ss.now()
+	quality := sm2Quality(correct, elapsed, ss.latency)
+	if quality >= 3 && c.Due.After(now) {
+		return
+	}
+	c.Ease = math.Max(sm2EaseMin, c.Ease+(0.1-float64(5-quality)*(0.08+float64(5-quality)*0.02)))
+	if quality < 3 {
+		c.Repetitions = 0
+		c.Interval = 0
+		c.Due = now.Add(sm2RelearnDelay)
+		return
+	}
+	c.Repetitions++
+	switch c.Repetitions {
+	case 1:
+		c.Interval = 24 * time.Hour
+	case 2:
+		c.Interval = 6 * 24 * time.Hour
+	default:
+		c.Interval = time.Duration(float64(c.Interval) * c.Ease)
+	}
+	c.Due = now.Add(c.Interval)
+}
+
+// Weight implements Scheduler.
+func (ss *sm2Scheduler) Weight(kana string) float64 {
+	c := ss.card(kana)
+	return (sm2EaseDefault / c.Ease) / float64(1+c.Repetitions)
+}
+
+// Mastered implements Scheduler.
+func (ss *sm2Scheduler) Mastered(kana string) bool {
+	return ss.card(kana).Repetitions >= sm2MasteredRepetitions
+}
+
+func (ss *sm2Scheduler) card(kana string) *card {
+	c, ok := ss.cards[kana]
+	if !ok {
+		c = &card{}
+		ss.cards[kana] = c
+	}
+	if c.Ease == 0 {
+		c.Ease = sm2EaseDefault
+	}
+	return c
+}
+
+// sm2Quality maps an answer to the 0-5 quality scale SM-2 expects.
+//
+// Latency is judged relative to the learner's median once there are
+// enough samples, and against fixed thresholds until then.
+func sm2Quality(correct bool, elapsed time.Duration, latency latencyPolicy) int {
+	switch {
+	case !correct:
+		return 1
+	case latency.IsSlow(elapsed):
+		return 3
+	case latency.IsFast(elapsed, sm2FastAnswer):
+		return 5
+	}
+	if _, ok := latency.median(); ok || elapsed < sm2SlowAnswer {
+		return 4
+	}
+	return 3
+}
+
+// leitnerScheduler implements a Leitner box system.
+//
+// Kana start in the first box and move up a box on each correct answer,
+// and back to the first box on each incorrect answer. Higher boxes are
+// reviewed less often. Correct answers that were slow stay in their box.
+type leitnerScheduler struct {
+	values  map[string]string
+	cards   map[string]*card
+	latency latencyPolicy
+	now     func() time.Time
+	random  *rand.Rand
+}
+
+// Next implements Scheduler.
+func (ls *leitnerScheduler) Next(exclude []string) string {
+	now := ls.now()
+	var due []string
+	lowest := leitnerBoxes + 1
+	for kana := range ls.values {
+		if listHas(exclude, kana) {
+			continue
+		}
+		c := ls.card(kana)
+		if c.Due.After(now) {
+			continue
+		}
+		if c.Box < lowest {
+			lowest = c.Box
+			due = due[:0]
+		}
+		if c.Box == lowest {
+			due = append(due, kana)
+		}
+	}
+	if len(due) > 0 {
+		sortKana(due)
+		return due[ls.random.Intn(len(due))]
+	}
+	return selectEarliestDue(ls.random, ls.values, exclude, ls.card)
+}
+
+// Record implements Scheduler.
+//
+// Like sm2, a correct answer to a card that isn't due yet doesn't move it
+// up a box.
+func (ls *leitnerScheduler) Record(kana string, correct bool, elapsed time.Duration) {
+	c := ls.card(kana)
+	now := ls.now()
+	switch {
+	case correct && c.Due.After(now):
+		return
+	case !correct:
+		c.Box = leitnerBoxDefault
+	case ls.latency.IsSlow(elapsed):
+	case c.Box < leitnerBoxes:
+		c.Box++
+	}
+	c.Due = now.Add(leitnerIntervals[c.Box])
+}
+
+// Weight implements Scheduler.
+func (ls *leitnerScheduler) Weight(kana string) float64 {
+	return math.Pow(2, float64(leitnerBoxes-ls.card(kana).Box))
+}
+
+// Mastered implements Scheduler.
+func (ls *leitnerScheduler) Mastered(kana string) bool {
+	return ls.card(kana).Box >= leitnerMasteredBox
+}
+
+func (ls *leitnerScheduler) card(kana string) *card {
+	c, ok := ls.cards[kana]
+	if !ok {
+		c = &card{}
+		ls.cards[kana] = c
+	}
+	if c.Box == 0 {
+		c.Box = leitnerBoxDefault
+	}
+	return c
+}
+
+// selectEarliestDue returns the kana that is due soonest, choosing randomly between ties.
+func selectEarliestDue(random *rand.Rand, values map[string]string, exclude []string, getCard func(string) *card) string {
+	if len(exclude) >= len(values) {
+		exclude = nil
+	}
+	var keys []string
+	for kana := range values {
+		if !listHas(exclude, kana) {
+			keys = append(keys, kana)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if due, otherDue := getCard(keys[i]).Due, getCard(keys[j]).Due; !due.Equal(otherDue) {
+			return due.Before(otherDue)
+		}
+		return kanaLess(keys[i], keys[j])
+	})
+	earliest := getCard(keys[0]).Due
+	var ties int
+	for ties < len(keys) && getCard(keys[ties]).Due.Equal(earliest) {
+		ties++
+	}
+	return keys[random.Intn(ties)]
+}
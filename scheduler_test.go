@@ -0,0 +1,162 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+func Test_newScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	p := newProfile("test")
+	for _, name := range []string{schedulerWeighted, schedulerSM2, schedulerLeitner} {
+		scheduler, err := newScheduler(name, romaji.Hiragana, p, latencyPolicy{}, testRandom(), time.Now)
+		assert.Nil(err)
+		assert.NotNil(scheduler)
+	}
+
+	_, err := newScheduler("not-a-scheduler", romaji.Hiragana, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.NotNil(err)
+	// the spaced repetition schedulers schedule by the clock they're given.
+	now := time.Date(2019, 10, 01, 12, 0, 0, 0, time.UTC)
+	for _, name := range []string{schedulerSM2, schedulerLeitner} {
+		p := newProfile("test")
+		scheduler, err := newScheduler(name, romaji.Hiragana, p, latencyPolicy{}, testRandom(), func() time.Time { return now })
+		assert.Nil(err)
+		scheduler.Record("あ", true, time.Second)
+		assert.True(p.Cards["あ"].Due.After(now), name)
+		assert.True(p.Cards["あ"].Due.Before(now.Add(30*24*time.Hour)), name)
+	}
+}
+
+func Test_weightedScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	p := newProfile("test")
+	p.init(romaji.Hiragana)
+	scheduler, err := newScheduler(schedulerWeighted, romaji.Hiragana, p, latencyPolicy{}, testRandom(), time.Now)
+	assert.Nil(err)
+
+	scheduler.Record("あ", false, time.Second)
+	assert.Equal(weightDefault*weightIncreaseFactor, scheduler.Weight("あ"))
+	scheduler.Record("い", true, time.Second)
+	assert.Equal(weightDefault/weightDecreaseFactor, scheduler.Weight("い"))
+
+	for x := 0; x < 32; x++ {
+		assert.NotEqual("あ", scheduler.Next([]string{"あ"}))
+	}
+}
+
+func Test_sm2Scheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2019, 10, 01, 12, 0, 0, 0, time.UTC)
+	values := map[string]string{"あ": "a", "い": "i"}
+	scheduler := &sm2Scheduler{values: values, cards: make(map[string]*card), now: func() time.Time { return now }, random: testRandom()}
+
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(1, scheduler.cards["あ"].Repetitions)
+	assert.Equal(now.Add(24*time.Hour), scheduler.cards["あ"].Due)
+	assert.Equal("い", scheduler.Next(nil), "new cards should be due before reviewed cards")
+
+	// answering before it's due doesn't change the schedule.
+	ease := scheduler.cards["あ"].Ease
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(1, scheduler.cards["あ"].Repetitions)
+	assert.Equal(24*time.Hour, scheduler.cards["あ"].Interval)
+	assert.Equal(now.Add(24*time.Hour), scheduler.cards["あ"].Due)
+	assert.Equal(ease, scheduler.cards["あ"].Ease)
+
+	now = now.Add(24 * time.Hour)
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(2, scheduler.cards["あ"].Repetitions)
+	assert.Equal(6*24*time.Hour, scheduler.cards["あ"].Interval)
+	ease = scheduler.cards["あ"].Ease
+
+	// but answering incorrectly early still sends it back.
+	now = now.Add(time.Hour)
+
+	scheduler.Record("あ", false, time.Second)
+	assert.Zero(scheduler.cards["あ"].Repetitions)
+	assert.True(scheduler.cards["あ"].Ease < ease)
+	assert.Equal(now.Add(sm2RelearnDelay), scheduler.cards["あ"].Due)
+}
+
+func Test_leitnerScheduler(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2019, 10, 01, 12, 0, 0, 0, time.UTC)
+	values := map[string]string{"あ": "a", "い": "i"}
+	scheduler := &leitnerScheduler{values: values, cards: make(map[string]*card), now: func() time.Time { return now }, random: testRandom()}
+
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(2, scheduler.cards["あ"].Box)
+	assert.Equal("い", scheduler.Next(nil))
+	assert.True(scheduler.Weight("あ") < scheduler.Weight("い"))
+
+	// answering before it's due doesn't move it up a box.
+	due := scheduler.cards["あ"].Due
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(2, scheduler.cards["あ"].Box)
+	assert.Equal(due, scheduler.cards["あ"].Due)
+
+	now = due
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(3, scheduler.cards["あ"].Box)
+
+	// but answering incorrectly early still moves it down.
+	now = now.Add(time.Minute)
+	scheduler.Record("あ", false, time.Second)
+	assert.Equal(leitnerBoxDefault, scheduler.cards["あ"].Box)
+}
+
+func Test_latencyPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	times := []time.Duration{time.Second, time.Second, time.Second, 2 * time.Second}
+	latency := latencyPolicy{SlowRatio: 2.0, MinSamples: 4, Times: func() []time.Duration { return times }}
+	assert.False(latency.IsSlow(1500 * time.Millisecond))
+	assert.True(latency.IsSlow(3 * time.Second))
+	assert.True(latency.IsFast(time.Second, 0))
+	assert.False(latency.IsFast(1500*time.Millisecond, time.Hour))
+
+	latency.MinSamples = 5
+	assert.False(latency.IsSlow(time.Minute), "slow answers need enough samples")
+	assert.True(latency.IsFast(time.Second, 2*time.Second))
+
+	latency = latencyPolicy{MinSamples: 1, Times: func() []time.Duration { return times }}
+	assert.False(latency.IsSlow(time.Minute), "a zero ratio disables slow answers")
+}
+
+func Test_weightedScheduler_slow(t *testing.T) {
+	assert := assert.New(t)
+
+	p := newProfile("test")
+	p.init(romaji.Hiragana)
+	p.KanaTimes["い"] = []time.Duration{time.Second, time.Second, time.Second}
+	scheduler, err := newScheduler(schedulerWeighted, romaji.Hiragana, p, latencyPolicy{SlowRatio: 2.0, MinSamples: 3, Times: p.times}, testRandom(), time.Now)
+	assert.Nil(err)
+
+	scheduler.Record("あ", true, 5*time.Second)
+	assert.Equal(weightDefault*weightSlowFactor, scheduler.Weight("あ"))
+	scheduler.Record("あ", true, time.Second)
+	assert.Equal(weightDefault, scheduler.Weight("あ"))
+}
+
+func Test_sm2Quality(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(1, sm2Quality(false, time.Second, latencyPolicy{}))
+	assert.Equal(5, sm2Quality(true, time.Second, latencyPolicy{}))
+	assert.Equal(4, sm2Quality(true, 3*time.Second, latencyPolicy{}))
+	assert.Equal(3, sm2Quality(true, 10*time.Second, latencyPolicy{}))
+
+	times := []time.Duration{4 * time.Second, 4 * time.Second}
+	latency := latencyPolicy{SlowRatio: 2.0, MinSamples: 2, Times: func() []time.Duration { return times }}
+	assert.Equal(5, sm2Quality(true, 3*time.Second, latency))
+	assert.Equal(4, sm2Quality(true, 6*time.Second, latency))
+	assert.Equal(3, sm2Quality(true, 9*time.Second, latency))
+}
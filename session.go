@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+var errNoPrompt = errors.New("there is no prompt to answer")
+
+// sessionConfig is everything a session needs to quiz a learner.
+type sessionConfig struct {
+	Profile       *profile
+	Values        map[string]string
+	Meanings      map[string]string
+	Scheduler     Scheduler
+	SchedulerName string
+	System        romaji.System
+	Mode          string
+	Choices       int
+	Direction     string
+	NormalizeKana func(string) string
+	// Progress unlocks rows as kana are mastered; nil unless progressive.
+	Progress *progression
+	// Events has each answer appended to it; nil to not log answers.
+	Events eventWriter
+	// NoSave keeps the profile from being saved when the session is closed.
+	NoSave bool
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+	// Random makes the session's random choices, and should be the source
+	// the scheduler uses too so that Seed replays the whole session.
+	Random *rand.Rand
+	// Seed is the seed Random was created with.
+	Seed int64
+	// AnswerTimeout is how long the learner has to answer each prompt
+	// before it's marked incorrect; zero for no limit.
+	AnswerTimeout time.Duration
+}
+
+// eventWriter is where a session logs answers.
+type eventWriter interface {
+	Write(event) error
+	Close() error
+}
+
+// Prompt is a question shown to the learner.
+type Prompt struct {
+	Kana      string
+	Question  string
+	Direction string
+	// Options are the numbered options in choice mode.
+	Options []string
+}
+
+// Result is the outcome of answering a prompt.
+type Result struct {
+	Prompt
+	Answer     string
+	Correct    bool
+	Expected   string
+	Correction string
+	Elapsed    time.Duration
+	// TimedOut is set if the prompt wasn't answered in time.
+	TimedOut bool
+	// Answered and CorrectTotal are the running score for the session.
+	Answered     int
+	CorrectTotal int
+	// Unlocked is the name of a row unlocked by the answer, if any.
+	Unlocked string
+}
+
+// Summary is the outcome of a session.
+type Summary struct {
+	Answered   int
+	Correct    int
+	Times      []time.Duration
+	Confusions []confusion
+	// Seed replays the session when passed to `--seed`, given the same
+	// flags and the profile as it was before the session.
+	Seed int64
+	// NoSave is set if the profile wasn't saved, i.e. it's the same
+	// (empty) profile the next time.
+	NoSave bool
+
+	// The profile's lifetime results for the kana in the session.
+	Values    map[string]string
+	Total     map[string]int
+	Incorrect map[string]int
+	KanaTimes map[string][]time.Duration
+	Scheduler Scheduler
+}
+
+// Session is the drill engine; it picks kana, checks answers and records results.
+//
+// It doesn't do any input or output itself, so it can be driven by any
+// Frontend (or directly by tests).
+type Session struct {
+	config     sessionConfig
+	id         string
+	history    []string
+	maxHistory int
+	pending    *pending
+	answered   int
+	correct    int
+	times      []time.Duration
+	confusions map[string]map[string]int
+}
+
+// pending is the prompt waiting for an answer.
+type pending struct {
+	prompt       Prompt
+	answers      []string
+	normalize    func(string) string
+	expected     string
+	correction   string
+	weightBefore float64
+	start        time.Time
+}
+
+// NewSession returns a new session.
+func NewSession(config sessionConfig) *Session {
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.NormalizeKana == nil {
+		config.NormalizeKana = kanaNormalizer(true, true)
+	}
+	if config.Random == nil {
+		config.Random = rand.New(rand.NewSource(config.Seed))
+	}
+	return &Session{
+		config:     config,
+		id:         config.Now().UTC().Format(time.RFC3339),
+		maxHistory: effectiveHistory(config.Values),
+		confusions: make(map[string]map[string]int),
+	}
+}
+
+// Next picks the next kana and returns the prompt for it.
+func (s *Session) Next() Prompt {
+	c := s.config
+	kana := c.Scheduler.Next(s.history)
+	s.history = listAddFixedLength(s.history, kana, s.maxHistory)
+
+	accepted := readings(kana, c.Values[kana], c.System)
+	p := &pending{
+		prompt: Prompt{
+			Kana:      kana,
+			Direction: selectDirection(c.Random, c.Direction),
+		},
+	}
+	if _, ok := scriptPairs[kana]; p.prompt.Direction == directionCross && !ok {
+		p.prompt.Direction = directionForward
+	}
+	switch {
+	case c.Mode == modeChoice:
+		var answer string
+		p.prompt.Question, answer, p.correction, p.prompt.Options = choiceQuestion(c.Random, c.Values, kana, p.prompt.Direction, c.System, c.Choices, c.Profile.Incorrect)
+		p.answers, p.normalize = []string{answer}, normalizeRomaji
+	case p.prompt.Direction == directionCross:
+		p.prompt.Question, p.answers, p.normalize = kana, []string{scriptPairs[kana]}, normalizeWidth
+		p.correction = scriptPairs[kana]
+	case p.prompt.Direction == directionReverse:
+		p.prompt.Question, p.answers, p.normalize = accepted[0], kanaWithReading(c.Values, kana, c.System), c.NormalizeKana
+		p.correction = strings.Join(p.answers, ", ")
+	default:
+		p.prompt.Question, p.answers, p.normalize = kana, accepted, normalizeRomaji
+		p.correction = strings.Join(p.answers, ", ")
+	}
+	p.expected = p.correction
+	if meaning := c.Meanings[kana]; meaning != "" {
+		p.correction = fmt.Sprintf("%s: %s", p.correction, meaning)
+	}
+
+	p.weightBefore = c.Scheduler.Weight(kana)
+	p.start = c.Now()
+	s.pending = p
+	return p.prompt
+}
+
+// Answer checks an answer to the current prompt and records the result.
+func (s *Session) Answer(actual string) (Result, error) {
+	return s.answer(actual, false)
+}
+
+// TimeOut records that the current prompt wasn't answered in time, which
+// counts as an incorrect answer.
+func (s *Session) TimeOut() (Result, error) {
+	return s.answer("", true)
+}
+
+func (s *Session) answer(actual string, timedOut bool) (Result, error) {
+	p := s.pending
+	if p == nil {
+		return Result{}, errNoPrompt
+	}
+	s.pending = nil
+	c := s.config
+	elapsed := c.Now().Sub(p.start)
+	actual = strings.TrimSpace(actual)
+
+	isCorrect := false
+	for _, expected := range p.answers {
+		if !timedOut && p.normalize(actual) == p.normalize(expected) {
+			isCorrect = true
+			break
+		}
+	}
+	actual = chosenOption(p.prompt.Options, actual)
+	kana := p.prompt.Kana
+
+	s.answered++
+	incrementCount(c.Profile.Total, kana)
+	c.Scheduler.Record(kana, isCorrect, elapsed)
+	if isCorrect {
+		s.correct++
+	} else {
+		incrementCount(c.Profile.Incorrect, kana)
+		recordConfusion(s.confusions, kana, actual)
+		recordConfusion(c.Profile.Confusions, kana, actual)
+	}
+	c.Profile.KanaTimes[kana] = append(c.Profile.KanaTimes[kana], elapsed)
+	s.times = append(s.times, elapsed)
+
+	result := Result{
+		Prompt:       p.prompt,
+		Answer:       actual,
+		Correct:      isCorrect,
+		Expected:     p.expected,
+		Correction:   p.correction,
+		Elapsed:      elapsed,
+		TimedOut:     timedOut,
+		Answered:     s.answered,
+		CorrectTotal: s.correct,
+	}
+
+	if c.Events != nil {
+		if err := c.Events.Write(event{
+			Timestamp:    p.start.UTC(),
+			Session:      s.id,
+			Profile:      c.Profile.Name,
+			Kana:         kana,
+			Script:       scriptName(kana),
+			Mode:         c.Mode,
+			Direction:    p.prompt.Direction,
+			Scheduler:    c.SchedulerName,
+			Expected:     p.expected,
+			Answer:       actual,
+			Correct:      isCorrect,
+			TimedOut:     timedOut,
+			Latency:      elapsed,
+			WeightBefore: p.weightBefore,
+			WeightAfter:  c.Scheduler.Weight(kana),
+		}); err != nil {
+			return result, err
+		}
+	}
+
+	if c.Progress != nil {
+		if unlocked, ok := c.Progress.advance(c.Values, c.Scheduler); ok {
+			result.Unlocked = unlocked.Name
+			s.maxHistory = effectiveHistory(c.Values)
+		}
+	}
+	return result, nil
+}
+
+// Summary returns the results of the session so far.
+func (s *Session) Summary() Summary {
+	return Summary{
+		Answered:   s.answered,
+		Correct:    s.correct,
+		Times:      copyDurations(s.times),
+		Confusions: topConfusions(s.confusions, confusionsShown),
+		Seed:       s.config.Seed,
+		NoSave:     s.config.NoSave,
+		Values:     s.config.Values,
+		Total:      s.config.Profile.Total,
+		Incorrect:  s.config.Profile.Incorrect,
+		KanaTimes:  s.config.Profile.KanaTimes,
+		Scheduler:  s.config.Scheduler,
+	}
+}
+
+// Close saves the profile (unless NoSave is set) and closes the event log.
+func (s *Session) Close() error {
+	if !s.config.NoSave {
+		if err := saveProfile(s.config.Profile); err != nil {
+			return err
+		}
+	}
+	if s.config.Events != nil {
+		return s.config.Events.Close()
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// testEvents collects the events written by a session.
+type testEvents struct {
+	events []event
+	closed bool
+}
+
+func (te *testEvents) Write(e event) error {
+	te.events = append(te.events, e)
+	return nil
+}
+
+func (te *testEvents) Close() error {
+	te.closed = true
+	return nil
+}
+
+// testClock is a clock that moves forward a second each time it's read.
+func testClock() func() time.Time {
+	now := time.Date(2019, 10, 10, 12, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func newTestSession(t *testing.T, values map[string]string, mode string) (*Session, *profile, *testEvents) {
+	t.Helper()
+	p := newProfile("test")
+	p.init(values)
+	random := testRandom()
+	clock := testClock()
+	scheduler, err := newScheduler(schedulerWeighted, values, p, latencyPolicy{}, random, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := new(testEvents)
+	return NewSession(sessionConfig{
+		Profile:       p,
+		Values:        values,
+		Scheduler:     scheduler,
+		SchedulerName: schedulerWeighted,
+		System:        romaji.Hepburn,
+		Mode:          mode,
+		Choices:       choicesDefault,
+		Direction:     directionForward,
+		Events:        events,
+		Now:           clock,
+		Random:        random,
+		Seed:          1,
+	}), p, events
+}
+
+func Test_Session(t *testing.T) {
+	assert := assert.New(t)
+
+	s, p, events := newTestSession(t, map[string]string{"ぬ": "nu"}, modeRecall)
+
+	_, err := s.Answer("nu")
+	assert.Equal(errNoPrompt, err, "answering without a prompt should fail")
+
+	prompt := s.Next()
+	assert.Equal("ぬ", prompt.Kana)
+	assert.Equal("ぬ", prompt.Question)
+	result, err := s.Answer(" NU ")
+	assert.Nil(err)
+	assert.True(result.Correct)
+	assert.Equal(time.Second, result.Elapsed)
+	assert.Equal(1, result.CorrectTotal)
+
+	s.Next()
+	result, err = s.Answer("su")
+	assert.Nil(err)
+	assert.False(result.Correct)
+	assert.Equal("nu", result.Correction)
+	assert.Equal(2, result.Answered)
+	assert.Equal(1, result.CorrectTotal)
+
+	assert.Equal(2, p.Total["ぬ"])
+	assert.Equal(1, p.Incorrect["ぬ"])
+	assert.Len(p.KanaTimes["ぬ"], 2)
+	assert.Equal(1, p.Confusions["ぬ"]["su"])
+
+	assert.Len(events.events, 2)
+	assert.Equal("su", events.events[1].Answer)
+	assert.Equal(s.id, events.events[1].Session)
+
+	summary := s.Summary()
+	assert.Equal(2, summary.Answered)
+	assert.Equal(1, summary.Correct)
+	assert.Len(summary.Times, 2)
+	assert.Equal([]confusion{{Kana: "ぬ", Answer: "su", Count: 1}}, summary.Confusions)
+}
+
+func Test_Session_choice(t *testing.T) {
+	assert := assert.New(t)
+
+	s, _, events := newTestSession(t, romaji.Hiragana, modeChoice)
+	prompt := s.Next()
+	assert.Len(prompt.Options, choicesDefault)
+
+	var wrong int
+	for index, option := range prompt.Options {
+		if option != romaji.Hiragana[prompt.Kana] {
+			wrong = index + 1
+		}
+	}
+	result, err := s.Answer(strconv.Itoa(wrong))
+	assert.Nil(err)
+	assert.False(result.Correct)
+	assert.Equal(prompt.Options[wrong-1], result.Answer, "the option picked should be recorded, not its number")
+	assert.Equal(prompt.Options[wrong-1], events.events[0].Answer)
+}
+
+func Test_Session_meanings(t *testing.T) {
+	assert := assert.New(t)
+
+	s, _, _ := newTestSession(t, map[string]string{"ねこ": "neko"}, modeRecall)
+	s.config.Meanings = map[string]string{"ねこ": "cat"}
+	s.Next()
+	result, err := s.Answer("inu")
+	assert.Nil(err)
+	assert.Equal("neko", result.Expected)
+	assert.Equal("neko: cat", result.Correction)
+}
+
+func Test_Session_seed(t *testing.T) {
+	assert := assert.New(t)
+
+	// sessions with the same seed and profile ask the same prompts.
+	run := func() ([]Prompt, Summary) {
+		s, _, _ := newTestSession(t, mergeSets(romaji.Hiragana, romaji.Katakana), modeChoice)
+		s.config.Direction = directionBoth
+		var prompts []Prompt
+		for x := 0; x < 16; x++ {
+			prompts = append(prompts, s.Next())
+			_, err := s.Answer("1")
+			assert.Nil(err)
+		}
+		return prompts, s.Summary()
+	}
+	prompts, summary := run()
+	replayed, _ := run()
+	assert.Equal(prompts, replayed)
+	assert.Equal(int64(1), summary.Seed)
+}
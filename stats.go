@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blend/go-sdk/ansi"
+)
+
+// Formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+const (
+	dayFormat           = "2006-01-02"
+	statsDaysDefault    = 7
+	statsWeakestDefault = 10
+)
+
+// kanaStats are the long term stats for a single kana.
+type kanaStats struct {
+	Kana     string        `json:"kana"`
+	Total    int           `json:"total"`
+	Correct  int           `json:"correct"`
+	Accuracy float64       `json:"accuracy"`
+	P50      time.Duration `json:"p50"`
+	P95      time.Duration `json:"p95"`
+	times    []time.Duration
+}
+
+// dayStats are the stats for a single day.
+type dayStats struct {
+	Day      string        `json:"day"`
+	Total    int           `json:"total"`
+	Correct  int           `json:"correct"`
+	Accuracy float64       `json:"accuracy"`
+	Studied  time.Duration `json:"studied"`
+}
+
+// stats are the long term progress stats computed from the event log.
+type stats struct {
+	Total         int           `json:"total"`
+	Correct       int           `json:"correct"`
+	Accuracy      float64       `json:"accuracy"`
+	Studied       time.Duration `json:"studied"`
+	Sessions      int           `json:"sessions"`
+	CurrentStreak int           `json:"currentStreak"`
+	LongestStreak int           `json:"longestStreak"`
+	Kana          []kanaStats   `json:"kana"`
+	Days          []dayStats    `json:"days"`
+	Weakest       []kanaStats   `json:"weakest"`
+	Confusions    []confusion   `json:"confusions"`
+}
+
+// runStats implements the `stats` subcommand.
+func runStats(flags *flagSet, args []string) error {
+	profileName := flags.StringP("profile", "p", profileDefault, "The profile to report on")
+	logPath := flags.String("log", "", "The event log to read (defaults to the profile's log)")
+	since := flags.String("since", "", "Only include answers since a date (e.g. 2019-10-01) or a duration ago (e.g. 72h or 30d)")
+	script := flags.String("script", "", "Only include a script (hiragana or katakana)")
+	days := flags.Int("days", statsDaysDefault, "The number of days to show the trend for")
+	weakest := flags.Int("weakest", statsWeakestDefault, "The number of weakest kana to show")
+	format := flags.StringP("format", "f", formatTable, "The output format (table, json or csv)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch *format {
+	case formatTable, formatJSON, formatCSV:
+	default:
+		return fmt.Errorf("invalid format: %q (expected one of %s, %s, %s)", *format, formatTable, formatJSON, formatCSV)
+	}
+	if *weakest < 0 {
+		return fmt.Errorf("invalid weakest: %d (the number of weakest kana to show can't be negative)", *weakest)
+	}
+	now := time.Now()
+	events, err := loadEvents(*profileName, *logPath, *since, *script, now)
+	if err != nil {
+		return err
+	}
+
+	results := computeStats(events, now, *days, *weakest)
+	switch *format {
+	case formatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(results)
+	case formatCSV:
+		return writeStatsCSV(os.Stdout, results)
+	default:
+		return writeStatsTable(os.Stdout, results)
+	}
+}
+
+// loadEvents reads the events in a profile's log (or the log at a given
+// path), filtered to those since a given date or duration and in a given script.
+//
+// A missing log is treated as an empty one.
+func loadEvents(profileName, logPath, since, script string, now time.Time) ([]event, error) {
+	sinceTime, err := parseSince(since, now)
+	if err != nil {
+		return nil, err
+	}
+	if logPath == "" {
+		if logPath, err = eventLogPath(profileName); err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(logPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []event
+	err = readEvents(f, func(e event) error {
+		if e.Timestamp.Before(sinceTime) || (script != "" && e.Script != script) {
+			return nil
+		}
+		events = append(events, e)
+		return nil
+	})
+	return events, err
+}
+
+// parseSince parses a `--since` value, which is either a date or a duration
+// ago (a go duration, or a number of days like 30d).
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if day, err := time.ParseInLocation(dayFormat, value, now.Location()); err == nil {
+		return day, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since: %q (expected a date like 2019-10-01 or a duration like 72h or 30d)", value)
+}
+
+// computeStats computes stats from a list of events.
+func computeStats(events []event, now time.Time, days, weakest int) stats {
+	var output stats
+	byKana := make(map[string]*kanaStats)
+	byDay := make(map[string]*dayStats)
+	sessions := make(map[string]bool)
+	confusions := make(map[string]map[string]int)
+
+	for _, e := range events {
+		output.Total++
+		output.Studied += e.Latency
+		sessions[e.Session] = true
+
+		ks, ok := byKana[e.Kana]
+		if !ok {
+			ks = &kanaStats{Kana: e.Kana}
+			byKana[e.Kana] = ks
+		}
+		ks.Total++
+		ks.times = append(ks.times, e.Latency)
+
+		day := e.Timestamp.In(now.Location()).Format(dayFormat)
+		ds, ok := byDay[day]
+		if !ok {
+			ds = &dayStats{Day: day}
+			byDay[day] = ds
+		}
+		ds.Total++
+		ds.Studied += e.Latency
+
+		if e.Correct {
+			output.Correct++
+			ks.Correct++
+			ds.Correct++
+		} else {
+			recordConfusion(confusions, e.Kana, e.Answer)
+		}
+	}
+	output.Accuracy = accuracy(output.Correct, output.Total)
+	output.Sessions = len(sessions)
+
+	var keys []string
+	for kana := range byKana {
+		keys = append(keys, kana)
+	}
+	sortKana(keys)
+	for _, kana := range keys {
+		ks := byKana[kana]
+		ks.Accuracy = accuracy(ks.Correct, ks.Total)
+		ks.P50 = percentileOfDuration(ks.times, 50.0)
+		ks.P95 = percentileOfDuration(ks.times, 95.0)
+		output.Kana = append(output.Kana, *ks)
+	}
+
+	output.Weakest = append([]kanaStats(nil), output.Kana...)
+	sort.SliceStable(output.Weakest, func(i, j int) bool {
+		if output.Weakest[i].Accuracy != output.Weakest[j].Accuracy {
+			return output.Weakest[i].Accuracy < output.Weakest[j].Accuracy
+		}
+		return output.Weakest[i].P95 > output.Weakest[j].P95
+	})
+	if len(output.Weakest) > weakest {
+		output.Weakest = output.Weakest[:weakest]
+	}
+
+	today := now.Format(dayFormat)
+	for offset := days - 1; offset >= 0; offset-- {
+		day := now.AddDate(0, 0, -offset).Format(dayFormat)
+		if ds, ok := byDay[day]; ok {
+			ds.Accuracy = accuracy(ds.Correct, ds.Total)
+			output.Days = append(output.Days, *ds)
+		} else {
+			output.Days = append(output.Days, dayStats{Day: day})
+		}
+	}
+	output.CurrentStreak, output.LongestStreak = streaks(byDay, today)
+	output.Confusions = topConfusions(confusions, confusionsShown)
+	return output
+}
+
+// streaks returns the current and longest runs of consecutive days studied.
+//
+// The current streak counts back from today, or from yesterday if
+// nothing has been studied yet today.
+func streaks(byDay map[string]*dayStats, today string) (current, longest int) {
+	var studied []string
+	for day := range byDay {
+		studied = append(studied, day)
+	}
+	sort.Strings(studied)
+
+	var run int
+	var previous time.Time
+	for _, day := range studied {
+		parsed, _ := time.Parse(dayFormat, day)
+		if !previous.IsZero() && parsed.Sub(previous) == 24*time.Hour {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		previous = parsed
+	}
+
+	day, _ := time.Parse(dayFormat, today)
+	if _, ok := byDay[today]; !ok {
+		day = day.AddDate(0, 0, -1)
+	}
+	for {
+		if _, ok := byDay[day.Format(dayFormat)]; !ok {
+			return
+		}
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+}
+
+// writeStatsTable writes stats as ansi tables.
+func writeStatsTable(wr io.Writer, results stats) error {
+	fmt.Fprintf(wr, "Total answered: %d (%s correct)\n", results.Total, formatAccuracy(results.Accuracy))
+	fmt.Fprintf(wr, "Time studied: %v over %d sessions\n", results.Studied.Round(time.Second), results.Sessions)
+	fmt.Fprintf(wr, "Streak: %d days (longest %d days)\n", results.CurrentStreak, results.LongestStreak)
+	if results.Total == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(wr, "Trend:")
+	var rows [][]string
+	for _, ds := range results.Days {
+		rows = append(rows, []string{ds.Day, strconv.Itoa(ds.Total), formatAccuracy(ds.Accuracy), fmt.Sprint(ds.Studied.Round(time.Second))})
+	}
+	if err := ansi.Table(wr, []string{"Day", "Total", "Accuracy", "Studied"}, rows); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(wr, "Weakest:")
+	if err := ansi.Table(wr, kanaStatsColumns, kanaStatsRows(results.Weakest)); err != nil {
+		return err
+	}
+
+	printConfusions(wr, results.Confusions)
+
+	fmt.Fprintln(wr, "Kana:")
+	return ansi.Table(wr, kanaStatsColumns, kanaStatsRows(results.Kana))
+}
+
+// writeStatsCSV writes the per kana stats as csv.
+func writeStatsCSV(wr io.Writer, results stats) error {
+	writer := csv.NewWriter(wr)
+	if err := writer.Write(kanaStatsColumns); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(kanaStatsRows(results.Kana)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// kanaStatsColumns are the column headers for per kana stats.
+var kanaStatsColumns = []string{"Kana", "Total", "Correct", "Accuracy", "P50", "P95"}
+
+// kanaStatsRows formats per kana stats as table rows.
+func kanaStatsRows(values []kanaStats) [][]string {
+	var rows [][]string
+	for _, ks := range values {
+		rows = append(rows, []string{
+			ks.Kana,
+			strconv.Itoa(ks.Total),
+			strconv.Itoa(ks.Correct),
+			formatAccuracy(ks.Accuracy),
+			fmt.Sprint(ks.P50.Round(time.Millisecond)),
+			fmt.Sprint(ks.P95.Round(time.Millisecond)),
+		})
+	}
+	return rows
+}
+
+// accuracy returns the fraction of answers that were correct.
+func accuracy(correct, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) / float64(total)
+}
+
+// formatAccuracy formats an accuracy as a percentage.
+func formatAccuracy(value float64) string {
+	return fmt.Sprintf("%.2f%%", value*100)
+}
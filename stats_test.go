@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func Test_parseSince(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2019, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseSince("", now)
+	assert.Nil(err)
+	assert.True(since.IsZero())
+
+	since, err = parseSince("2019-10-01", now)
+	assert.Nil(err)
+	assert.Equal(time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), since)
+
+	since, err = parseSince("3d", now)
+	assert.Nil(err)
+	assert.Equal(time.Date(2019, 10, 7, 12, 0, 0, 0, time.UTC), since)
+
+	since, err = parseSince("2h", now)
+	assert.Nil(err)
+	assert.Equal(time.Date(2019, 10, 10, 10, 0, 0, 0, time.UTC), since)
+
+	_, err = parseSince("yesterday", now)
+	assert.NotNil(err)
+}
+
+func Test_runStats_invalid(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	cmd, _ := findCommand("stats")
+	assert.NotNil(runStats(newFlagSet(cmd), []string{"--weakest=-1"}))
+	assert.NotNil(runStats(newFlagSet(cmd), []string{"--format=xml"}))
+}
+
+func Test_computeStats(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Date(2019, 10, 10, 12, 0, 0, 0, time.UTC)
+	day := func(offset int) time.Time { return now.AddDate(0, 0, -offset) }
+	events := []event{
+		{Timestamp: day(5), Session: "a", Kana: "か", Correct: true, Latency: time.Second},
+		{Timestamp: day(2), Session: "b", Kana: "か", Correct: false, Latency: 3 * time.Second},
+		{Timestamp: day(1), Session: "c", Kana: "あ", Correct: true, Latency: time.Second},
+		{Timestamp: day(1), Session: "c", Kana: "シ", Answer: "tsu", Correct: false, Latency: 2 * time.Second},
+		{Timestamp: day(0), Session: "d", Kana: "あ", Correct: true, Latency: time.Second},
+	}
+
+	results := computeStats(events, now, 3, 2)
+	assert.Equal(5, results.Total)
+	assert.Equal(3, results.Correct)
+	assert.Equal(8*time.Second, results.Studied)
+	assert.Equal(4, results.Sessions)
+	assert.Equal(3, results.CurrentStreak)
+	assert.Equal(3, results.LongestStreak)
+
+	assert.Len(results.Kana, 3)
+	assert.Equal("あ", results.Kana[0].Kana, "kana should be in chart order")
+	assert.Equal("か", results.Kana[1].Kana)
+	assert.Equal("シ", results.Kana[2].Kana)
+	assert.Equal(0.5, results.Kana[1].Accuracy)
+
+	assert.Len(results.Weakest, 2)
+	assert.Equal("シ", results.Weakest[0].Kana)
+	assert.Equal("か", results.Weakest[1].Kana)
+
+	assert.Len(results.Days, 3)
+	assert.Equal("2019-10-08", results.Days[0].Day)
+	assert.Equal(1, results.Days[0].Total)
+	assert.Equal(0.5, results.Days[1].Accuracy)
+	assert.Equal(time.Second, results.Days[2].Studied)
+
+	assert.Len(results.Confusions, 1, "wrong answers that weren't typed shouldn't count")
+	assert.Equal(confusion{Kana: "シ", Answer: "tsu", Count: 1}, results.Confusions[0])
+}
+
+func Test_streaks(t *testing.T) {
+	assert := assert.New(t)
+
+	byDay := map[string]*dayStats{
+		"2019-10-01": {}, "2019-10-02": {}, "2019-10-03": {},
+		"2019-10-08": {}, "2019-10-09": {},
+	}
+	current, longest := streaks(byDay, "2019-10-10")
+	assert.Equal(2, current, "a streak should carry over until the end of today")
+	assert.Equal(3, longest)
+
+	current, _ = streaks(byDay, "2019-10-11")
+	assert.Zero(current)
+}
+
+func Test_writeStatsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(writeStatsCSV(buffer, stats{Kana: []kanaStats{
+		{Kana: "か", Total: 2, Correct: 1, Accuracy: 0.5, P50: time.Second, P95: 2 * time.Second},
+	}}))
+	assert.Equal("Kana,Total,Correct,Accuracy,P50,P95\nか,2,1,50.00%,1s,2s\n", buffer.String())
+}
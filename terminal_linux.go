@@ -0,0 +1,31 @@
+//go:build linux && (386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// The ioctl request to flush a terminal's queues (TCFLSH) and the queue
+// of input that's been received but not read (TCIFLUSH), which syscall
+// doesn't define on every architecture.
+//
+// TCFLSH has this number on the architectures in the build tag; others
+// (e.g. mips and ppc64) use a different one and fall back to terminal_other.go.
+const (
+	ioctlFlush      = 0x540B
+	ioctlFlushInput = 0
+)
+
+// flushInput discards anything typed into a terminal that hasn't been read
+// yet, e.g. half an answer to a prompt that ran out of time.
+func flushInput(f *os.File) {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return
+	}
+	_ = conn.Control(func(fd uintptr) {
+		_, _, _ = syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlFlush, ioctlFlushInput)
+	})
+}
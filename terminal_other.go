@@ -0,0 +1,11 @@
+//go:build !linux || !(386 || amd64 || arm || arm64 || loong64 || riscv64 || s390x)
+
+package main
+
+import "os"
+
+// flushInput discards anything typed into a terminal that hasn't been read
+// yet; it's only supported on linux on the architectures in terminal_linux.go,
+// elsewhere half an answer to a prompt that ran out of time is read as the
+// answer to the next one.
+func flushInput(f *os.File) {}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/wcharczuk/kana/romaji"
+)
+
+// defaultWordList is the word list used by `--words` when `--wordlist` isn't set.
+//
+// The format is one word per line, optionally followed by whitespace and
+// a meaning. Blank lines and lines starting with `#` are ignored.
+const defaultWordList = `# hiragana
+ねこ	cat
+いぬ	dog
+さかな	fish
+やま	mountain
+かわ	river
+みず	water
+ひと	person
+はな	flower
+そら	sky
+くるま	car
+がっこう	school
+きって	stamp
+ざっし	magazine
+いっしょ	together
+まっちゃ	matcha
+ちょっと	a little
+きんえん	no smoking
+げんいん	cause
+ほんや	bookstore
+せんせい	teacher
+でんわ	telephone
+しんぶん	newspaper
+ありがとう	thank you
+おかあさん	mother
+とうきょう	Tokyo
+きょう	today
+りょこう	travel
+ひゃく	hundred
+しゅくだい	homework
+# katakana
+コーヒー	coffee
+ケーキ	cake
+テレビ	television
+カメラ	camera
+ノート	notebook
+ラーメン	ramen
+コンピューター	computer
+スーパー	supermarket
+ベッド	bed
+パン	bread
+タクシー	taxi
+ホテル	hotel
+ジュース	juice
+ニュース	news
+チョコレート	chocolate
+パーティー	party
+フォーク	fork
+`
+
+// word is an entry in a word list.
+type word struct {
+	Kana    string
+	Meaning string
+}
+
+// readWordList reads a word list from a file, or the default word list if path is empty.
+func readWordList(path string) ([]word, error) {
+	if path == "" {
+		return parseWordList(strings.NewReader(defaultWordList))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseWordList(f)
+}
+
+// wordSet reads a word list into a set of words and their canonical
+// romanizations, along with their meanings.
+//
+// Words are only included if every script they use is included.
+func wordSet(path string, includeHiragana, includeKatakana bool) (values, meanings map[string]string, err error) {
+	words, err := readWordList(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	values = make(map[string]string)
+	meanings = make(map[string]string)
+	for _, w := range words {
+		if (!includeHiragana && romaji.ToKatakana(w.Kana) != w.Kana) || (!includeKatakana && romaji.ToHiragana(w.Kana) != w.Kana) {
+			continue
+		}
+		roman, err := romaji.ToRomaji(w.Kana)
+		if err != nil {
+			return nil, nil, err
+		}
+		values[w.Kana] = roman
+		meanings[w.Kana] = w.Meaning
+	}
+	return values, meanings, nil
+}
+
+// parseWordList parses a word list.
+func parseWordList(r io.Reader) ([]word, error) {
+	var output []word
+	scanner := bufio.NewScanner(r)
+	var line int
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if _, err := romaji.ToRomaji(fields[0]); err != nil {
+			return nil, fmt.Errorf("word list line %d: %v", line, err)
+		}
+		output = append(output, word{
+			Kana:    fields[0],
+			Meaning: strings.Join(fields[1:], " "),
+		})
+	}
+	return output, scanner.Err()
+}
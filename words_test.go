@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blend/go-sdk/assert"
+)
+
+func Test_parseWordList(t *testing.T) {
+	assert := assert.New(t)
+
+	words, err := parseWordList(strings.NewReader("# comment\n\nねこ\tcat\nコーヒー\n"))
+	assert.Nil(err)
+	assert.Len(words, 2)
+	assert.Equal("cat", words[0].Meaning)
+	assert.Equal("コーヒー", words[1].Kana)
+
+	_, err = parseWordList(strings.NewReader("ねこ\nxyz\n"))
+	assert.NotNil(err)
+
+	words, err = readWordList("")
+	assert.Nil(err)
+	assert.NotEmpty(words)
+}
+
+func Test_wordSet(t *testing.T) {
+	assert := assert.New(t)
+
+	values, meanings, err := wordSet("", true, false)
+	assert.Nil(err)
+	assert.Equal("neko", values["ねこ"])
+	assert.Equal("cat", meanings["ねこ"])
+	assert.Empty(values["コーヒー"])
+}